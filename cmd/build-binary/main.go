@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
 
 	"github.com/alexandremahdhaoui/tooling/internal/util"
 	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
@@ -34,22 +39,196 @@ func run() error {
 		return flaterrors.Join(err, errors.New("error reading environment variables"))
 	}
 
-	if err := os.Setenv("CG0_ENABLED", "0"); err != nil {
+	outputName, err := renderOutputName(envs)
+	if err != nil {
+		return flaterrors.Join(err, errors.New("error rendering OUTPUT_NAME"))
+	}
+
+	outputPath := fmt.Sprintf("./build/bin/%s", outputName)
+
+	// Remove the previous output to ensure no stale binary is left behind if the build fails.
+	if envs.CleanFirst {
+		if err := removePreviousBinary(outputPath); err != nil {
+			return err
+		}
+	}
+
+	if err := util.RunCmdWithStdPipes(goBuildCmd(envs, outputPath)); err != nil {
 		return err
 	}
 
+	if envs.UPX {
+		if err := compress(outputPath, envs.UPXRequired); err != nil {
+			return err
+		}
+	}
+
+	if len(envs.VerifyArgs) > 0 {
+		if err := verify(outputPath, envs.VerifyArgs); err != nil {
+			return err
+		}
+	}
+
+	if envs.SymlinkLatest {
+		if err := symlinkLatest(outputPath, envs.BinaryName); err != nil {
+			return err
+		}
+	}
+
+	if envs.GithubOutput != "" {
+		if err := writeGithubOutput(envs.GithubOutput, envs.BinaryName, outputPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// goBuildCmd returns the go build command writing the binary to outputPath.
+func goBuildCmd(envs Envs, outputPath string) *exec.Cmd {
 	cmd := "go"
 	args := []string{
 		"build",
 		"-ldflags", envs.GoBuildLDFlags,
-		"-o", fmt.Sprintf("./build/bin/%s", envs.BinaryName),
+		"-o", outputPath,
 		fmt.Sprintf("./cmd/%s", envs.BinaryName),
 	}
 
-	if err := util.RunCmdWithStdPipes(exec.Command(cmd, args...)); err != nil {
+	// The environment is set on the command rather than on the process, so it does not leak into other commands.
+	goBuild := exec.Command(cmd, args...)
+	goBuild.Env = append(os.Environ(), "CGO_ENABLED=0")
+
+	return goBuild
+}
+
+// removePreviousBinary removes the binary at outputPath. A missing binary is not an error.
+func removePreviousBinary(outputPath string) error {
+	if err := os.Remove(outputPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return flaterrors.Join(err, errors.New("error removing previous binary"))
+	}
+
+	return nil
+}
+
+// verify runs the binary at outputPath with args as a smoke test.
+func verify(outputPath string, args []string) error {
+	if err := util.RunCmdWithStdPipes(exec.Command(outputPath, args...)); err != nil {
+		return flaterrors.Join(err, fmt.Errorf("binary built at %q but verification failed", outputPath)) //nolint:err113
+	}
+
+	return nil
+}
+
+// renderOutputName returns the name of the built binary. It defaults to the binary name, unless an OUTPUT_NAME template
+// is provided, e.g. "{{.Name}}-{{.Version}}-{{.OS}}-{{.Arch}}".
+func renderOutputName(envs Envs) (string, error) {
+	if envs.OutputName == "" {
+		return envs.BinaryName, nil
+	}
+
+	tmpl, err := template.New("outputName").Parse(envs.OutputName)
+	if err != nil {
+		return "", err
+	}
+
+	// GOOS and GOARCH are only read here to name the binary: go build reads them from the environment.
+	data := struct {
+		Name    string
+		Version string
+		OS      string
+		Arch    string
+	}{
+		Name:    envs.BinaryName,
+		Version: envs.Version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+
+	if envs.GOOS != "" {
+		data.OS = envs.GOOS
+	}
+
+	if envs.GOARCH != "" {
+		data.Arch = envs.GOARCH
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	if buf.Len() == 0 || strings.ContainsRune(buf.String(), '/') {
+		return "", fmt.Errorf("invalid output name %q", buf.String()) //nolint:err113
+	}
+
+	return buf.String(), nil
+}
+
+// symlinkLatest points the "<name>-latest" symlink next to the binary at outputPath to the binary, e.g. when it is named
+// after its version with OUTPUT_NAME. The symlink is replaced atomically: it is created under a temporary name and
+// renamed over the previous one.
+func symlinkLatest(outputPath, name string) error {
+	dir := filepath.Dir(outputPath)
+	link := filepath.Join(dir, fmt.Sprintf("%s-latest", name))
+	tmp := filepath.Join(dir, fmt.Sprintf(".%s-latest.%d.tmp", name, os.Getpid()))
+
+	// The target is relative to the symlink, hence the build directory can be moved.
+	if err := os.Symlink(filepath.Base(outputPath), tmp); err != nil {
+		return flaterrors.Join(err, fmt.Errorf("cannot create symlink %q", link)) //nolint:err113
+	}
+
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return flaterrors.Join(err, fmt.Errorf("cannot create symlink %q", link)) //nolint:err113
+	}
+
+	return nil
+}
+
+// writeGithubOutput appends the location of the built binary to the GitHub Actions output file, so downstream steps can
+// consume it as "artifact_<name>".
+func writeGithubOutput(path, name, outputPath string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return flaterrors.Join(err, fmt.Errorf("cannot open GitHub output file %q", path)) //nolint:err113
+	}
+
+	if _, err := fmt.Fprintf(f, "artifact_%s=%s\n", name, outputPath); err != nil {
+		_ = f.Close()
+		return flaterrors.Join(err, fmt.Errorf("cannot write GitHub output to %q", path)) //nolint:err113
+	}
+
+	return f.Close()
+}
+
+// compress compresses the binary with upx. A missing upx executable is only an error if required is true.
+func compress(path string, required bool) error {
+	if _, err := exec.LookPath("upx"); err != nil {
+		if required {
+			return flaterrors.Join(err, errors.New("upx is required to compress the binary"))
+		}
+
+		fmt.Printf("⚠️ upx not found, skipping compression of %q\n", path)
+
+		return nil
+	}
+
+	before, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := util.RunCmdWithStdPipes(exec.Command("upx", "--best", path)); err != nil {
+		return flaterrors.Join(err, errors.New("error compressing binary"))
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
 		return err
 	}
 
+	fmt.Printf("📦 Binary compressed from %d to %d bytes\n", before.Size(), after.Size())
+
 	return nil
 }
 
@@ -58,6 +237,21 @@ func run() error {
 type Envs struct {
 	BinaryName     string `env:"BINARY_NAME,required"`
 	GoBuildLDFlags string `env:"GO_BUILD_LDFLAGS,required"`
+
+	VerifyArgs []string `env:"VERIFY_ARGS"`
+	CleanFirst bool     `env:"CLEAN_FIRST"`
+
+	UPX         bool `env:"UPX"`
+	UPXRequired bool `env:"UPX_REQUIRED"`
+
+	GithubOutput string `env:"GITHUB_OUTPUT_FILE"`
+
+	SymlinkLatest bool `env:"FORGE_SYMLINK_LATEST"`
+
+	OutputName string `env:"OUTPUT_NAME"`
+	Version    string `env:"VERSION"`
+	GOOS       string `env:"GOOS"`
+	GOARCH     string `env:"GOARCH"`
 }
 
 // ----------------------------------------------------- PRINT HELPERS ----------------------------------------------- //
@@ -69,6 +263,17 @@ BINARY_NAME="%s" GO_BUILD_LDFLAGS="%s" %s [BINARY_NAME]
 Required environment variables:
     BINARY_NAME         Name of the binary to build.
     GO_BUILD_LDFLAGS    Go linker flags.
+
+Optional environment variables:
+    VERIFY_ARGS         Comma-separated args used to run the built binary as a smoke test (e.g. "--version").
+    CLEAN_FIRST         Removes the previously built binary before building when set to "true".
+    UPX                 Compresses the built binary with "upx --best" when set to "true".
+    UPX_REQUIRED        Fails the build if upx is not installed when set to "true". Otherwise compression is skipped.
+    OUTPUT_NAME         Template of the name of the built binary, e.g. "{{.Name}}-{{.Version}}-{{.OS}}-{{.Arch}}".
+                        Defaults to BINARY_NAME. OS and Arch default to the current platform unless GOOS or GOARCH are set.
+    VERSION             Version used to render OUTPUT_NAME.
+    FORGE_SYMLINK_LATEST Creates or updates a "<BINARY_NAME>-latest" symlink to the built binary when set to "true".
+    GITHUB_OUTPUT_FILE  Appends "artifact_<BINARY_NAME>=<path>" to this file, e.g. "$GITHUB_OUTPUT" in GitHub Actions.
 `
 
 func printUsage() {
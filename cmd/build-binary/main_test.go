@@ -0,0 +1,225 @@
+//go:build unit
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOutputName(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		envs        Envs
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "defaults to the binary name",
+			envs:     Envs{BinaryName: "tool"}, //nolint:exhaustruct
+			expected: "tool",
+		},
+		{
+			name: "renders the template",
+			envs: Envs{ //nolint:exhaustruct
+				BinaryName: "tool",
+				OutputName: "{{.Name}}-{{.Version}}-{{.OS}}-{{.Arch}}",
+				Version:    "v1.2.3",
+				GOOS:       "darwin",
+				GOARCH:     "arm64",
+			},
+			expected: "tool-v1.2.3-darwin-arm64",
+		},
+		{
+			name: "defaults to the current platform",
+			envs: Envs{ //nolint:exhaustruct
+				BinaryName: "tool",
+				OutputName: "{{.Name}}-{{.OS}}-{{.Arch}}",
+			},
+			expected: "tool-" + runtime.GOOS + "-" + runtime.GOARCH,
+		},
+		{
+			name:        "rejects an empty name",
+			envs:        Envs{BinaryName: "tool", OutputName: "{{.Version}}"}, //nolint:exhaustruct
+			expectedErr: true,
+		},
+		{
+			name:        "rejects a name containing a slash",
+			envs:        Envs{BinaryName: "tool", OutputName: "../{{.Name}}"}, //nolint:exhaustruct
+			expectedErr: true,
+		},
+		{
+			name:        "rejects an unknown field",
+			envs:        Envs{BinaryName: "tool", OutputName: "{{.Unknown}}"}, //nolint:exhaustruct
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := renderOutputName(tc.envs)
+
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGoBuildCmd(t *testing.T) {
+	t.Setenv("CGO_ENABLED", "1")
+
+	cmd := goBuildCmd(Envs{BinaryName: "tool", GoBuildLDFlags: "-s -w"}, "./build/bin/tool") //nolint:exhaustruct
+
+	assert.Equal(t, []string{"go", "build", "-ldflags", "-s -w", "-o", "./build/bin/tool", "./cmd/tool"}, cmd.Args)
+
+	// exec.Cmd uses the last value of duplicated keys.
+	assert.Equal(t, "CGO_ENABLED=0", cmd.Env[len(cmd.Env)-1])
+	assert.Equal(t, "1", os.Getenv("CGO_ENABLED"))
+}
+
+func TestRemovePreviousBinary(t *testing.T) {
+	t.Run("removes an existing binary", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "tool")
+		require.NoError(t, os.WriteFile(outputPath, []byte("stale"), 0o600))
+
+		require.NoError(t, removePreviousBinary(outputPath))
+		assert.NoFileExists(t, outputPath)
+	})
+
+	t.Run("ignores a missing binary", func(t *testing.T) {
+		assert.NoError(t, removePreviousBinary(filepath.Join(t.TempDir(), "tool")))
+	})
+}
+
+func TestCompress(t *testing.T) {
+	newBinary := func(t *testing.T) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "tool")
+		require.NoError(t, os.WriteFile(path, []byte("uncompressed binary"), 0o600))
+
+		return path
+	}
+
+	t.Run("skips compression when upx is missing and not required", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		path := newBinary(t)
+
+		require.NoError(t, compress(path, false))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "uncompressed binary", string(b))
+	})
+
+	t.Run("fails when upx is missing and required", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		assert.Error(t, compress(newBinary(t), true))
+	})
+
+	t.Run("compresses the binary with upx", func(t *testing.T) {
+		// The fake upx is called with "--best <path>".
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "upx"), //nolint:gosec
+			[]byte("#!/bin/sh\nprintf compressed > \"$2\"\n"), 0o755))
+		t.Setenv("PATH", dir)
+
+		path := newBinary(t)
+
+		require.NoError(t, compress(path, true))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "compressed", string(b))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	newBinary := func(t *testing.T, exitCode int) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "tool")
+		require.NoError(t, os.WriteFile(path, //nolint:gosec
+			[]byte(fmt.Sprintf("#!/bin/sh\nexit %d\n", exitCode)), 0o755))
+
+		return path
+	}
+
+	t.Run("succeeds when the binary exits with 0", func(t *testing.T) {
+		assert.NoError(t, verify(newBinary(t, 0), []string{"--version"}))
+	})
+
+	t.Run("fails when the binary exits with 1", func(t *testing.T) {
+		path := newBinary(t, 1)
+
+		err := verify(path, []string{"--version"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("binary built at %q but verification failed", path))
+	})
+}
+
+func TestWriteGithubOutput(t *testing.T) {
+	t.Run("appends the artifact without truncating the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "github_output")
+		require.NoError(t, os.WriteFile(path, []byte("previous=step\n"), 0o600))
+
+		require.NoError(t, writeGithubOutput(path, "tool", "./build/bin/tool"))
+		require.NoError(t, writeGithubOutput(path, "other", "./build/bin/other"))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "previous=step\nartifact_tool=./build/bin/tool\nartifact_other=./build/bin/other\n", string(b))
+	})
+
+	t.Run("fails if the file does not exist", func(t *testing.T) {
+		assert.Error(t, writeGithubOutput(filepath.Join(t.TempDir(), "missing"), "tool", "./build/bin/tool"))
+	})
+}
+
+func TestSymlinkLatest(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "tool-latest")
+
+	build := func(t *testing.T, outputName string) {
+		t.Helper()
+
+		outputPath := filepath.Join(dir, outputName)
+		require.NoError(t, os.WriteFile(outputPath, []byte(outputName), 0o600))
+		require.NoError(t, symlinkLatest(outputPath, "tool"))
+	}
+
+	t.Run("creates the symlink", func(t *testing.T) {
+		build(t, "tool-v1.0.0")
+
+		target, err := os.Readlink(link)
+		require.NoError(t, err)
+		assert.Equal(t, "tool-v1.0.0", target)
+
+		b, err := os.ReadFile(link)
+		require.NoError(t, err)
+		assert.Equal(t, "tool-v1.0.0", string(b))
+	})
+
+	t.Run("repoints the symlink on rebuild", func(t *testing.T) {
+		build(t, "tool-v1.1.0")
+
+		target, err := os.Readlink(link)
+		require.NoError(t, err)
+		assert.Equal(t, "tool-v1.1.0", target)
+
+		// No temporary symlink is left behind.
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/alexandremahdhaoui/tooling/internal/util"
 	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
@@ -27,30 +30,82 @@ func main() {
 // ----------------------------------------------------- ENVS ------------------------------------------------------- //
 
 func run() error {
-	envs := Envs{} //nolint:exhaustruct // unmarshal
-
-	if err := env.Parse(&envs); err != nil {
+	envs, err := readEnvs()
+	if err != nil {
 		printUsage()
 		return flaterrors.Join(err, errors.New("error reading environment variables"))
 	}
 
+	if envs.PushRetries < 0 {
+		printUsage()
+		return fmt.Errorf("PUSH_RETRIES must not be negative, got %d", envs.PushRetries) //nolint:err113
+	}
+
+	if err := checkContainerEngine(envs.ContainerEngine); err != nil {
+		return err
+	}
+
 	wd, err := os.Getwd()
 	if err != nil {
 		return err
 	}
 
 	cmd := envs.ContainerEngine
-	args := []string{
-		"run", "-i",
-		"-v", fmt.Sprintf("%s:/workspace", wd),
-		"gcr.io/kaniko-project/executor:latest",
-		"-f", fmt.Sprintf("./containers/%s/Containerfile", envs.ContainerName),
+	volumes := []string{"-v", fmt.Sprintf("%s:/workspace", wd)}
+	kanikoArgs := []string{"-f", containerfilePath(envs.ContainerName)}
+
+	if envs.ContainerfileContent != "" {
+		contentVolume, contentArgs, cleanup, err := containerfileContentArgs(envs)
+		if err != nil {
+			return err
+		}
+
+		defer cleanup()
+
+		volumes = append(volumes, contentVolume...)
+		kanikoArgs = contentArgs
+	}
+
+	buildArgs := append(envs.BuildArgs, buildArgsFromEnv(envs.EnvBuildArgPrefix, os.Environ())...)
+
+	for _, buildArg := range buildArgs {
+		kanikoArgs = append(kanikoArgs, "--build-arg", buildArg)
+	}
+
+	// Builds are always performed by kaniko, regardless of the container engine running it.
+	if envs.Squash {
+		kanikoArgs = append(kanikoArgs, "--single-snapshot")
 	}
 
-	for _, buildArg := range envs.BuildArgs {
-		args = append(args, "--build-arg", buildArg)
+	// Only the push is retried: kaniko does not rebuild the image between attempts.
+	if envs.PushRetries > 0 {
+		kanikoArgs = append(kanikoArgs, fmt.Sprintf("--push-retry=%d", envs.PushRetries))
 	}
 
+	if envs.RegistryCACert != "" {
+		caVolume, caArgs, err := registryCACertArgs(envs)
+		if err != nil {
+			return err
+		}
+
+		volumes = append(volumes, caVolume...)
+		kanikoArgs = append(kanikoArgs, caArgs...)
+	}
+
+	if envs.KanikoTarDir != "" {
+		tarVolume, tarArgs, err := kanikoTarArgs(envs)
+		if err != nil {
+			return err
+		}
+
+		volumes = append(volumes, tarVolume...)
+		kanikoArgs = append(kanikoArgs, tarArgs...)
+	}
+
+	args := append([]string{"run", "-i"}, volumes...)
+	args = append(args, "gcr.io/kaniko-project/executor:latest")
+	args = append(args, kanikoArgs...)
+
 	switch len(envs.Destinations) {
 	default:
 		for _, dest := range envs.Destinations {
@@ -67,13 +122,182 @@ func run() error {
 	return nil
 }
 
+// buildArgsFromEnv returns the environ entries starting with prefix as build args, with the prefix stripped. E.g.
+// "BUILD_ARG_GOPROXY=https://proxy.golang.org" becomes "GOPROXY=https://proxy.golang.org".
+func buildArgsFromEnv(prefix string, environ []string) []string {
+	out := make([]string, 0)
+
+	if prefix == "" {
+		return out
+	}
+
+	for _, kv := range environ {
+		if buildArg, ok := strings.CutPrefix(kv, prefix); ok && !strings.HasPrefix(buildArg, "=") {
+			out = append(out, buildArg)
+		}
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// checkContainerEngine ensures the container engine can be found on PATH, and returns an actionable error otherwise.
+func checkContainerEngine(engine string) error {
+	if _, err := exec.LookPath(engine); err != nil {
+		alternative := "docker"
+		if filepath.Base(engine) == "docker" {
+			alternative = "podman"
+		}
+
+		return flaterrors.Join(err, fmt.Errorf( //nolint:err113
+			"%s not found; install %s or set CONTAINER_ENGINE=%s", engine, engine, alternative))
+	}
+
+	return nil
+}
+
+func containerfilePath(containerName string) string {
+	return fmt.Sprintf("./containers/%s/Containerfile", containerName)
+}
+
+const containerfileMountPath = "/kaniko-containerfile/Containerfile"
+
+var errContainerfileConflict = errors.New("CONTAINERFILE_CONTENT cannot be used when a Containerfile exists")
+
+// containerfileContentArgs writes envs.ContainerfileContent to a temporary file and returns the volume flags mounting it,
+// the kaniko flags using it and a cleanup function removing it. The content is mutually exclusive with the Containerfile
+// of the container on disk.
+func containerfileContentArgs(envs Envs) ([]string, []string, func(), error) {
+	if _, err := os.Stat(containerfilePath(envs.ContainerName)); err == nil {
+		return nil, nil, nil, flaterrors.Join(errContainerfileConflict,
+			fmt.Errorf("found %q", containerfilePath(envs.ContainerName))) //nolint:err113
+	}
+
+	f, err := os.CreateTemp("", "Containerfile-*")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cleanup := func() {
+		_ = os.Remove(f.Name())
+	}
+
+	if _, err := f.WriteString(envs.ContainerfileContent); err != nil {
+		_ = f.Close()
+		cleanup()
+
+		return nil, nil, nil, err
+	}
+
+	if err := f.Close(); err != nil {
+		cleanup()
+
+		return nil, nil, nil, err
+	}
+
+	volume := []string{"-v", fmt.Sprintf("%s:%s:ro", f.Name(), containerfileMountPath)}
+	args := []string{"-f", containerfileMountPath}
+
+	return volume, args, cleanup, nil
+}
+
+const registryCACertMountPath = "/kaniko-registry-ca/ca.crt"
+
+// registryCACertArgs returns the volume flags mounting envs.RegistryCACert and the kaniko flags trusting it for the
+// registry of each destination.
+func registryCACertArgs(envs Envs) ([]string, []string, error) {
+	caCert, err := filepath.Abs(envs.RegistryCACert)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := os.Stat(caCert); err != nil {
+		return nil, nil, flaterrors.Join(err, fmt.Errorf("cannot read REGISTRY_CA_CERT %q", caCert)) //nolint:err113
+	}
+
+	volume := []string{"-v", fmt.Sprintf("%s:%s:ro", caCert, registryCACertMountPath)}
+	args := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, dest := range envs.Destinations {
+		// E.g. "registry.local:5000/foo/bar:latest" is pushed to "registry.local:5000".
+		host, _, ok := strings.Cut(dest, "/")
+		if !ok {
+			continue
+		}
+
+		if _, ok := seen[host]; ok {
+			continue
+		}
+
+		seen[host] = struct{}{}
+		args = append(args, "--registry-certificate", fmt.Sprintf("%s=%s", host, registryCACertMountPath))
+	}
+
+	return volume, args, nil
+}
+
+const kanikoTarMountDir = "/kaniko-tar"
+
+// kanikoTarArgs returns the volume flags mounting envs.KanikoTarDir and the kaniko flags writing the image tarball
+// into it. The tar dir is mounted separately from the workspace to avoid polluting the repository.
+func kanikoTarArgs(envs Envs) ([]string, []string, error) {
+	tarDir, err := filepath.Abs(envs.KanikoTarDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(tarDir, 0o755); err != nil {
+		return nil, nil, err
+	}
+
+	volume := []string{"-v", fmt.Sprintf("%s:%s", tarDir, kanikoTarMountDir)}
+	args := []string{"--tar-path", filepath.Join(kanikoTarMountDir, fmt.Sprintf("%s.tar", envs.ContainerName))}
+
+	// kaniko requires a destination to name the image stored in the tarball.
+	if len(envs.Destinations) == 0 {
+		args = append(args, "-d", envs.ContainerName)
+	}
+
+	return volume, args, nil
+}
+
 // ----------------------------------------------------- ENVS ------------------------------------------------------- //
 
 type Envs struct {
 	ContainerEngine string   `env:"CONTAINER_ENGINE,required"`
 	ContainerName   string   `env:"CONTAINER_NAME,required"`
-	BuildArgs       []string `env:"BUILD_ARGS,required"`
+	BuildArgs       []string `env:"BUILD_ARGS"`
 	Destinations    []string `env:"DESTINATIONS"`
+	KanikoTarDir    string   `env:"KANIKO_TAR_DIR"`
+	Squash          bool     `env:"SQUASH"`
+	PushRetries     int      `env:"PUSH_RETRIES"`
+	RegistryCACert  string   `env:"REGISTRY_CA_CERT"`
+
+	ContainerfileContent string `env:"CONTAINERFILE_CONTENT"`
+
+	EnvBuildArgPrefix string `env:"ENV_BUILD_ARG_PREFIX"`
+}
+
+const (
+	envBuildArgPrefixKey     = "ENV_BUILD_ARG_PREFIX"
+	defaultEnvBuildArgPrefix = "BUILD_ARG_"
+)
+
+func readEnvs() (Envs, error) {
+	out := Envs{} //nolint:exhaustruct // unmarshal
+
+	if err := env.Parse(&out); err != nil {
+		return Envs{}, err
+	}
+
+	// envDefault also applies to empty values: the default is set here so that an empty prefix disables forwarding.
+	if _, ok := os.LookupEnv(envBuildArgPrefixKey); !ok {
+		out.EnvBuildArgPrefix = defaultEnvBuildArgPrefix
+	}
+
+	return out, nil
 }
 
 // ----------------------------------------------------- PRINT HELPERS ----------------------------------------------- //
@@ -85,10 +309,16 @@ CONTAINER_ENGINE=%q CONTAINER_NAME=%q BUILD_ARGS=%q %s [BINARY_NAME]
 Required environment variables:
     CONTAINER_ENGINE    string			Container engine such as podman or docker.
     CONTAINER_NAME      string      Name of the container to build.
-    BUILD_ARGS          []string		List of build args (e.g. "GO_BUILD_LDFLAGS=\"-X main.BuildTimestamp=$(TIMESTAMP)\"").
 
 Optional environment variables:
+    BUILD_ARGS          []string		List of build args (e.g. "GO_BUILD_LDFLAGS=\"-X main.BuildTimestamp=$(TIMESTAMP)\"").
+    ENV_BUILD_ARG_PREFIX string     Env vars with this prefix are forwarded as build args without the prefix. Defaults to "BUILD_ARG_". Set to "" to disable.
     DESTINATIONS        []string		List of destinations (e.g. "docker.io/alexandremahdhaoui/test:latest").
+    KANIKO_TAR_DIR      string      Directory outside the workspace where the image tarball is written.
+    SQUASH              bool        Squashes the filesystem changes of the image into a single layer.
+    CONTAINERFILE_CONTENT string    Inline Containerfile used instead of "./containers/<CONTAINER_NAME>/Containerfile".
+    REGISTRY_CA_CERT    string      Path to a CA certificate trusted when pushing to the registries of DESTINATIONS.
+    PUSH_RETRIES        int         Number of times a failed push is retried, with exponential backoff.
 `
 
 func printUsage() {
@@ -0,0 +1,254 @@
+//go:build unit
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildArgsFromEnv(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"BUILD_ARG_GOPROXY=https://proxy.golang.org",
+		"BUILD_ARG_EMPTY=",
+		"BUILD_ARG_=no-key",
+		"NOT_BUILD_ARG_FOO=bar",
+		"BUILD_ARG_CGO_ENABLED=0",
+	}
+
+	for _, tc := range []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{
+			name:   "strips the prefix and sorts the build args",
+			prefix: "BUILD_ARG_",
+			expected: []string{
+				"CGO_ENABLED=0",
+				"EMPTY=",
+				"GOPROXY=https://proxy.golang.org",
+			},
+		},
+		{
+			name:     "ignores env vars without the prefix",
+			prefix:   "CUSTOM_",
+			expected: []string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, buildArgsFromEnv(tc.prefix, environ))
+		})
+	}
+}
+
+func TestReadEnvsBuildArgPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prefix   *string
+		expected []string
+	}{
+		{
+			name:     "defaults to BUILD_ARG_ when unset",
+			prefix:   nil,
+			expected: []string{"GOPROXY=https://proxy.golang.org"},
+		},
+		{
+			name:     "is disabled by an empty prefix",
+			prefix:   ptr(""),
+			expected: []string{},
+		},
+		{
+			name:     "uses a custom prefix",
+			prefix:   ptr("CUSTOM_"),
+			expected: []string{"FOO=bar"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONTAINER_ENGINE", "podman")
+			t.Setenv("CONTAINER_NAME", "test")
+			t.Setenv("BUILD_ARG_GOPROXY", "https://proxy.golang.org")
+			t.Setenv("CUSTOM_FOO", "bar")
+
+			// t.Setenv restores the previous value on cleanup, even if the variable is unset in between.
+			t.Setenv(envBuildArgPrefixKey, "")
+
+			if tc.prefix == nil {
+				require.NoError(t, os.Unsetenv(envBuildArgPrefixKey))
+			} else {
+				t.Setenv(envBuildArgPrefixKey, *tc.prefix)
+			}
+
+			envs, err := readEnvs()
+			require.NoError(t, err)
+
+			actual := make([]string, 0)
+
+			for _, buildArg := range buildArgsFromEnv(envs.EnvBuildArgPrefix, os.Environ()) {
+				if buildArg == "GOPROXY=https://proxy.golang.org" || buildArg == "FOO=bar" {
+					actual = append(actual, buildArg)
+				}
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestKanikoTarArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		destinations []string
+		expectedArgs []string
+	}{
+		{
+			name:         "writes the tarball of the pushed image",
+			destinations: []string{"registry.local/foo:latest"},
+			expectedArgs: []string{"--tar-path", "/kaniko-tar/foo.tar"},
+		},
+		{
+			name:         "names the image after the container without destination",
+			destinations: nil,
+			expectedArgs: []string{"--tar-path", "/kaniko-tar/foo.tar", "-d", "foo"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tarDir := filepath.Join(t.TempDir(), "nested", "tar")
+
+			volume, args, err := kanikoTarArgs(Envs{ //nolint:exhaustruct
+				ContainerName: "foo",
+				Destinations:  tc.destinations,
+				KanikoTarDir:  tarDir,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"-v", tarDir + ":/kaniko-tar"}, volume)
+			assert.Equal(t, tc.expectedArgs, args)
+			assert.DirExists(t, tarDir)
+		})
+	}
+
+	t.Run("fails if the tar dir cannot be created", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		_, _, err := kanikoTarArgs(Envs{ContainerName: "foo", KanikoTarDir: filepath.Join(file, "tar")}) //nolint:exhaustruct
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckContainerEngine(t *testing.T) {
+	t.Run("suggests the alternative engine when not found", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		err := checkContainerEngine("podman")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "podman not found; install podman or set CONTAINER_ENGINE=docker")
+
+		err = checkContainerEngine("docker")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "docker not found; install docker or set CONTAINER_ENGINE=podman")
+	})
+
+	t.Run("succeeds when found on PATH", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "podman"), []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec
+		t.Setenv("PATH", dir)
+
+		assert.NoError(t, checkContainerEngine("podman"))
+	})
+}
+
+func TestContainerfileContentArgs(t *testing.T) {
+	const content = "FROM scratch\n"
+
+	t.Run("mounts the content as the Containerfile", func(t *testing.T) {
+		chdir(t, t.TempDir())
+
+		volume, args, cleanup, err := containerfileContentArgs(Envs{ //nolint:exhaustruct
+			ContainerName:        "foo",
+			ContainerfileContent: content,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, volume, 2)
+		assert.Equal(t, "-v", volume[0])
+		assert.True(t, strings.HasSuffix(volume[1], ":/kaniko-containerfile/Containerfile:ro"))
+		assert.Equal(t, []string{"-f", "/kaniko-containerfile/Containerfile"}, args)
+
+		path := strings.TrimSuffix(volume[1], ":/kaniko-containerfile/Containerfile:ro")
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b))
+
+		cleanup()
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("conflicts with the Containerfile of the container", func(t *testing.T) {
+		chdir(t, t.TempDir())
+		require.NoError(t, os.MkdirAll(filepath.Join("containers", "foo"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join("containers", "foo", "Containerfile"), []byte(content), 0o600))
+
+		_, _, _, err := containerfileContentArgs(Envs{ //nolint:exhaustruct
+			ContainerName:        "foo",
+			ContainerfileContent: content,
+		})
+		assert.True(t, errors.Is(err, errContainerfileConflict))
+	})
+}
+
+func TestRegistryCACertArgs(t *testing.T) {
+	t.Run("trusts the CA once per distinct registry", func(t *testing.T) {
+		caCert := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(caCert, []byte("cert"), 0o600))
+
+		volume, args, err := registryCACertArgs(Envs{ //nolint:exhaustruct
+			RegistryCACert: caCert,
+			Destinations: []string{
+				"registry.local:5000/foo:latest",
+				"registry.local:5000/foo:v1",
+				"registry.local/foo:latest",
+				"no-registry",
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"-v", caCert + ":/kaniko-registry-ca/ca.crt:ro"}, volume)
+		assert.Equal(t, []string{
+			"--registry-certificate", "registry.local:5000=/kaniko-registry-ca/ca.crt",
+			"--registry-certificate", "registry.local=/kaniko-registry-ca/ca.crt",
+		}, args)
+	})
+
+	t.Run("fails if the CA does not exist", func(t *testing.T) {
+		_, _, err := registryCACertArgs(Envs{ //nolint:exhaustruct
+			RegistryCACert: filepath.Join(t.TempDir(), "missing.crt"),
+			Destinations:   []string{"registry.local/foo:latest"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot read REGISTRY_CA_CERT")
+	})
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
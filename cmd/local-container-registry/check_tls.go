@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+)
+
+const checkTLSCommand = "check-tls"
+
+var (
+	errCheckingTLS          = errors.New("error checking TLS certificate")
+	errNoPEMBlock           = errors.New("no PEM block found")
+	errCertificateExpired   = errors.New("certificate expired")
+	errCertificateNotActive = errors.New("certificate not yet valid")
+)
+
+// checkTLS reports the validity period of the CA certificate located at CaCrtPath, and fails if it is not valid.
+func checkTLS() error {
+	config, err := project.ReadConfig()
+	if err != nil {
+		return flaterrors.Join(err, errCheckingTLS)
+	}
+
+	b, err := os.ReadFile(config.LocalContainerRegistry.CaCrtPath)
+	if err != nil {
+		return flaterrors.Join(err, errCheckingTLS)
+	}
+
+	cert, err := parseCertificate(b)
+	if err != nil {
+		return flaterrors.Join(err, errCheckingTLS)
+	}
+
+	now := time.Now()
+	daysUntilExpiry := int(cert.NotAfter.Sub(now).Hours() / 24) //nolint:gomnd
+
+	_, _ = fmt.Fprintf(os.Stdout, "NotBefore:         %s\n", cert.NotBefore.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(os.Stdout, "NotAfter:          %s\n", cert.NotAfter.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(os.Stdout, "Days until expiry: %d\n", daysUntilExpiry)
+
+	if err := checkCertificate(b, now); err != nil {
+		return flaterrors.Join(err, errCheckingTLS)
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "✅ Certificate %q is valid\n", config.LocalContainerRegistry.CaCrtPath)
+
+	return nil
+}
+
+// checkCertificate returns an error if the PEM-encoded certificate is not valid at now.
+func checkCertificate(pemBytes []byte, now time.Time) error {
+	cert, err := parseCertificate(pemBytes)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case now.After(cert.NotAfter):
+		return errCertificateExpired
+	case now.Before(cert.NotBefore):
+		return errCertificateNotActive
+	}
+
+	return nil
+}
+
+func parseCertificate(pemBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errNoPEMBlock
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
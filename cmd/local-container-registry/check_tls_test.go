@@ -0,0 +1,80 @@
+//go:build unit
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertificate returns a PEM-encoded self-signed certificate valid from notBefore to notAfter.
+func newCertificate(t *testing.T, notBefore, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{ //nolint:exhaustruct
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "local-container-registry"}, //nolint:exhaustruct
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}) //nolint:exhaustruct
+}
+
+func TestCheckCertificate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	for _, tc := range []struct {
+		name        string
+		pem         []byte
+		expectedErr error
+	}{
+		{
+			name:        "valid",
+			pem:         newCertificate(t, now.Add(-day), now.Add(day)),
+			expectedErr: nil,
+		},
+		{
+			name:        "expired",
+			pem:         newCertificate(t, now.Add(-2*day), now.Add(-day)),
+			expectedErr: errCertificateExpired,
+		},
+		{
+			name:        "not yet valid",
+			pem:         newCertificate(t, now.Add(day), now.Add(2*day)),
+			expectedErr: errCertificateNotActive,
+		},
+		{
+			name:        "not PEM-encoded",
+			pem:         []byte("not a certificate"),
+			expectedErr: errNoPEMBlock,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkCertificate(tc.pem, now)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
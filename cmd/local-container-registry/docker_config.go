@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+)
+
+const writeDockerConfigCommand = "write-docker-config"
+
+type dockerAuth struct {
+	Auth string `json:"auth"`
+}
+
+var errWritingDockerConfig = errors.New("error writing docker config")
+
+// writeDockerConfig writes a docker config.json authenticating to the local container registry with the credentials
+// written during setup. If the config.json exists, the registry is merged into its auths.
+func writeDockerConfig(args []string) error {
+	flags := flag.NewFlagSet(writeDockerConfigCommand, flag.ContinueOnError)
+	out := flags.String("out", "config.json", "path to the generated docker config")
+
+	if err := flags.Parse(args); err != nil {
+		return flaterrors.Join(err, errWritingDockerConfig)
+	}
+
+	config, err := project.ReadConfig()
+	if err != nil {
+		return flaterrors.Join(err, errWritingDockerConfig)
+	}
+
+	b, err := os.ReadFile(config.LocalContainerRegistry.CredentialPath)
+	if err != nil {
+		return flaterrors.Join(err, errWritingDockerConfig)
+	}
+
+	creds := Credentials{} //nolint:exhaustruct // unmarshal
+
+	if err := yaml.Unmarshal(b, &creds); err != nil {
+		return flaterrors.Join(err, errWritingDockerConfig)
+	}
+
+	containerRegistry := NewContainerRegistry(nil, config.LocalContainerRegistry.Namespace, false, "", nil)
+	host := fmt.Sprintf("%s:%d", containerRegistry.FQDN(), containerRegistry.Port())
+	auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+
+	if err := mergeDockerConfig(*out, host, dockerAuth{Auth: auth}); err != nil {
+		return flaterrors.Join(err, errWritingDockerConfig)
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "✅ Docker config for %q written to %q\n", host, *out)
+
+	return nil
+}
+
+// mergeDockerConfig sets the auth of host in the docker config at path, preserving its other auths and settings.
+func mergeDockerConfig(path, host string, auth dockerAuth) error {
+	config := make(map[string]json.RawMessage)
+	auths := make(map[string]json.RawMessage)
+
+	if b, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(b, &config); err != nil {
+			return flaterrors.Join(err, fmt.Errorf("cannot parse existing docker config %q", path)) //nolint:err113
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if raw, ok := config["auths"]; ok {
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return flaterrors.Join(err, fmt.Errorf("cannot parse auths of docker config %q", path)) //nolint:err113
+		}
+	}
+
+	var err error
+
+	if auths[host], err = json.Marshal(auth); err != nil {
+		return err
+	}
+
+	if config["auths"], err = json.Marshal(auths); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,110 @@
+//go:build unit
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	dockerConfigProject = `name: test
+localContainerRegistry:
+  enabled: true
+  credentialPath: .ignore.credentials.yaml
+  namespace: lcr
+`
+	dockerConfigCredentials = `username: user
+password: pass
+`
+)
+
+func TestWriteDockerConfig(t *testing.T) {
+	const host = "local-container-registry.lcr.svc.cluster.local:5000"
+
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	for _, tc := range []struct {
+		name     string
+		existing string
+		expected map[string]any
+	}{
+		{
+			name:     "writes the auths of the registry",
+			existing: "",
+			expected: map[string]any{
+				"auths": map[string]any{host: map[string]any{"auth": auth}},
+			},
+		},
+		{
+			name:     "merges into an existing config",
+			existing: `{"auths": {"docker.io": {"auth": "other"}}, "credsStore": "desktop"}`,
+			expected: map[string]any{
+				"auths": map[string]any{
+					"docker.io": map[string]any{"auth": "other"},
+					host:        map[string]any{"auth": auth},
+				},
+				"credsStore": "desktop",
+			},
+		},
+		{
+			name:     "overrides a previous auth of the registry",
+			existing: `{"auths": {"` + host + `": {"auth": "stale"}}}`,
+			expected: map[string]any{
+				"auths": map[string]any{host: map[string]any{"auth": auth}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			chdir(t, dir)
+
+			require.NoError(t, os.WriteFile(".project.yaml", []byte(dockerConfigProject), 0o600))
+			require.NoError(t, os.WriteFile(".ignore.credentials.yaml", []byte(dockerConfigCredentials), 0o600))
+
+			out := filepath.Join(dir, "docker", "config.json")
+
+			if tc.existing != "" {
+				require.NoError(t, os.MkdirAll(filepath.Dir(out), 0o755))
+				require.NoError(t, os.WriteFile(out, []byte(tc.existing), 0o600))
+			}
+
+			require.NoError(t, writeDockerConfig([]string{"--out", out}))
+
+			b, err := os.ReadFile(out)
+			require.NoError(t, err)
+
+			actual := make(map[string]any)
+			require.NoError(t, json.Unmarshal(b, &actual))
+			assert.Equal(t, tc.expected, actual)
+
+			// The config holds credentials.
+			info, err := os.Stat(out)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		})
+	}
+
+	t.Run("fails on an invalid existing config", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(out, []byte("not json"), 0o600))
+
+		assert.Error(t, mergeDockerConfig(out, host, dockerAuth{Auth: auth}))
+	})
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+)
+
+// dryRunSetup prints the actions of the setup steps without performing them.
+func dryRunSetup(w io.Writer, steps []setupStep) {
+	actions := make([]string, 0)
+
+	for _, step := range steps {
+		actions = append(actions, step.actions...)
+	}
+
+	printDryRun(w, actions...)
+}
+
+// dryRunTeardown prints the actions performed by teardown without performing them.
+func dryRunTeardown(w io.Writer, config project.Config) {
+	printDryRun(w,
+		fmt.Sprintf("delete namespace %q", config.LocalContainerRegistry.Namespace),
+		fmt.Sprintf("set KUBECONFIG=%q", kubeconfig(config)),
+		fmt.Sprintf("delete cert-manager manifests %q", certManagerManifests),
+	)
+}
+
+func printDryRun(w io.Writer, actions ...string) {
+	for _, action := range actions {
+		_, _ = fmt.Fprintf(w, "🔍 [dry-run] would %s\n", action)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build unit
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunSetup(t *testing.T) {
+	const (
+		basePlan = `🔍 [dry-run] would create namespace "lcr" if missing
+🔍 [dry-run] would set KUBECONFIG="/tmp/kubeconfig"
+🔍 [dry-run] would write credentials to ".ignore.cred.yaml"
+🔍 [dry-run] would hash credentials with "docker" using image "docker.io/httpd:2"
+🔍 [dry-run] would create secret lcr/local-container-registry-credentials
+🔍 [dry-run] would install cert-manager helm chart in namespace "cert-manager"
+🔍 [dry-run] would create issuer lcr/local-container-registry-tls
+🔍 [dry-run] would create certificate lcr/local-container-registry-tls for "local-container-registry.lcr.svc.cluster.local"
+🔍 [dry-run] would create configmap lcr/local-container-registry-config
+🔍 [dry-run] would create service lcr/local-container-registry
+`
+		pvcPlan = `🔍 [dry-run] would create persistent volume claim lcr/local-container-registry-storage of size "5Gi"
+`
+		deploymentPlan = `🔍 [dry-run] would create deployment lcr/local-container-registry using image "docker.io/registry:2"
+🔍 [dry-run] would await readiness of deployment lcr/local-container-registry
+`
+	)
+
+	for _, tc := range []struct {
+		name       string
+		persistent bool
+		expected   string
+	}{
+		{
+			name:       "ephemeral storage",
+			persistent: false,
+			expected:   basePlan + deploymentPlan,
+		},
+		{
+			name:       "persistent storage",
+			persistent: true,
+			expected:   basePlan + pvcPlan + deploymentPlan,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", "/tmp/kubeconfig")
+
+			config := project.Config{ //nolint:exhaustruct
+				LocalContainerRegistry: project.LocalContainerRegistry{
+					Enabled:        true,
+					CredentialPath: ".ignore.cred.yaml",
+					CaCrtPath:      ".ignore.ca.crt",
+					Namespace:      "lcr",
+					Persistent:     tc.persistent,
+					StorageSize:    "5Gi",
+				},
+			}
+			envs := Envs{ContainerEngineExecutable: "docker", DryRun: true}
+
+			// The plan is printed from the steps run by setup.
+			steps := newSetupSteps(context.Background(), config, envs, nil, NewEventualConfig())
+			assert.Len(t, steps, 4)
+
+			buf := bytes.NewBuffer(make([]byte, 0))
+			dryRunSetup(buf, steps)
+
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/caarlos0/env/v11"
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/alexandremahdhaoui/tooling/pkg/eventualconfig"
 	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
 	"github.com/alexandremahdhaoui/tooling/pkg/project"
 )
@@ -26,6 +29,7 @@ const (
 
 type Envs struct {
 	ContainerEngineExecutable string `env:"CONTAINER_ENGINE"`
+	DryRun                    bool   `env:"DRY_RUN"`
 }
 
 var errReadingEnvVars = errors.New("reading environment variables")
@@ -53,13 +57,29 @@ func main() {
 		os.Exit(0)
 	}
 
-	if err := setup(); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+	// write-docker-config
+	if len(os.Args) > 1 && os.Args[1] == writeDockerConfigCommand {
+		if err := writeDockerConfig(os.Args[2:]); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+			os.Exit(1)
+		}
 
-		if err := teardown(); err != nil {
+		os.Exit(0)
+	}
+
+	// check-tls
+	if len(os.Args) > 1 && os.Args[1] == checkTLSCommand {
+		if err := checkTLS(); err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+			os.Exit(1)
 		}
 
+		os.Exit(0)
+	}
+
+	// setup rolls back the steps it completed on failure.
+	if err := setup(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
 		os.Exit(1)
 	}
 }
@@ -86,59 +106,130 @@ func setup() error {
 		return flaterrors.Join(err, errSettingLocalContainerRegistry)
 	}
 
-	eventualConfig := NewEventualConfig()
+	// II. Create client. A dry run does not connect to the cluster: it only prints the steps.
+	var cl client.Client
 
-	// II. Create client.
-	cl, err := createKubeClient(config)
-	if err != nil {
-		return flaterrors.Join(err, errSettingLocalContainerRegistry)
+	if !envs.DryRun {
+		cl, err = createKubeClient(config)
+		if err != nil {
+			return flaterrors.Join(err, errSettingLocalContainerRegistry)
+		}
 	}
 
-	/// III. Initialize adapters
-	containerRegistry := NewContainerRegistry(
-		cl,
-		config.LocalContainerRegistry.Namespace,
-		eventualConfig,
-	)
-	k8s := NewK8s(cl, config.Kindenv.KubeconfigPath, config.LocalContainerRegistry.Namespace)
-
-	cred := NewCredential(
-		cl,
-		envs.ContainerEngineExecutable,
-		config.LocalContainerRegistry.CredentialPath,
-		config.LocalContainerRegistry.Namespace,
-		eventualConfig)
+	steps := newSetupSteps(ctx, config, envs, cl, NewEventualConfig())
 
-	tls := NewTLS(
-		cl,
-		config.LocalContainerRegistry.CaCrtPath,
-		config.LocalContainerRegistry.Namespace,
-		containerRegistry.FQDN(),
-		eventualConfig)
+	if envs.DryRun {
+		dryRunSetup(os.Stdout, steps)
+		return nil
+	}
 
-	// IV. Set up K8s
-	if err := k8s.Setup(ctx); err != nil {
+	if err := runSetupSteps(ctx, steps); err != nil {
 		return flaterrors.Join(err, errSettingLocalContainerRegistry)
 	}
 
-	// V. Set up credentials.
-	if err := cred.Setup(ctx); err != nil {
-		return flaterrors.Join(err, errSettingLocalContainerRegistry)
+	// How to make required images available in the container registry?
+
+	_, _ = fmt.Fprintln(os.Stdout, "✅ Successfully set up "+Name)
+
+	return nil
+}
+
+// setupStep is a step of the setup and the teardown rolling it back. Its actions describe the step in dry runs.
+type setupStep struct {
+	actions  []string
+	setup    func(ctx context.Context) error
+	teardown func() error
+}
+
+// newSetupSteps returns the steps of the setup in the order they run. cl may be nil if the steps are not run.
+func newSetupSteps(
+	ctx context.Context,
+	config project.Config,
+	envs Envs,
+	cl client.Client,
+	eventualConfig eventualconfig.EventualConfig,
+) []setupStep {
+	lcr := config.LocalContainerRegistry
+	ns := lcr.Namespace
+
+	// III. Initialize adapters
+	containerRegistry := NewContainerRegistry(cl, ns, lcr.Persistent, lcr.StorageSize, eventualConfig)
+	k8s := NewK8s(cl, kubeconfig(config), ns)
+	cred := NewCredential(cl, envs.ContainerEngineExecutable, lcr.CredentialPath, ns, eventualConfig)
+	tls := NewTLS(cl, lcr.CaCrtPath, ns, containerRegistry.FQDN(), eventualConfig)
+
+	registryActions := []string{
+		fmt.Sprintf("create configmap %s/%s", ns, containerRegistry.ConfigMapName()),
+		fmt.Sprintf("create service %s/%s", ns, Name),
 	}
 
-	// VI. Set up TLS
-	if err := tls.Setup(ctx); err != nil {
-		return flaterrors.Join(err, errSettingLocalContainerRegistry)
+	if lcr.Persistent {
+		registryActions = append(registryActions, fmt.Sprintf("create persistent volume claim %s/%s of size %q",
+			ns, containerRegistry.PVCName(), containerRegistry.StorageSize()))
 	}
 
-	// VII. Set up container registry in k8s
-	if err := containerRegistry.Setup(ctx); err != nil {
-		return flaterrors.Join(err, errSettingLocalContainerRegistry)
+	registryActions = append(registryActions,
+		fmt.Sprintf("create deployment %s/%s using image %q", ns, Name, containerRegistryImage),
+		fmt.Sprintf("await readiness of deployment %s/%s", ns, Name),
+	)
+
+	return []setupStep{
+		{ // IV. Set up K8s
+			actions: []string{
+				fmt.Sprintf("create namespace %q if missing", ns),
+				fmt.Sprintf("set KUBECONFIG=%q", kubeconfig(config)),
+			},
+			setup:    k8s.Setup,
+			teardown: func() error { return k8s.Teardown(ctx) },
+		},
+		{ // V. Set up credentials.
+			actions: []string{
+				fmt.Sprintf("write credentials to %q", lcr.CredentialPath),
+				fmt.Sprintf("hash credentials with %q using image %q", envs.ContainerEngineExecutable,
+					htpasswdContainerImage),
+				fmt.Sprintf("create secret %s/%s", ns, credSecName),
+			},
+			setup:    cred.Setup,
+			teardown: cred.Teardown,
+		},
+		{ // VI. Set up TLS
+			actions: []string{
+				"install cert-manager helm chart in namespace \"cert-manager\"",
+				fmt.Sprintf("create issuer %s/%s", ns, tls.ResourceName()),
+				fmt.Sprintf("create certificate %s/%s for %q", ns, tls.ResourceName(), containerRegistry.FQDN()),
+			},
+			setup:    tls.Setup,
+			teardown: tls.Teardown,
+		},
+		{ // VII. Set up container registry in k8s. Its resources are deleted alongside the namespace.
+			actions:  registryActions,
+			setup:    containerRegistry.Setup,
+			teardown: nil,
+		},
 	}
+}
 
-	// How to make required images available in the container registry?
+// runSetupSteps runs the steps in order. If a step fails, the teardowns of the failed step and of the previous steps are
+// run in reverse order. The teardown of a step is registered before the step runs, so a step failing partway is also
+// rolled back: teardowns must tolerate missing resources.
+func runSetupSteps(ctx context.Context, steps []setupStep) error {
+	rollbacks := make([]func() error, 0, len(steps))
 
-	_, _ = fmt.Fprintln(os.Stdout, "✅ Successfully set up "+Name)
+	for _, step := range steps {
+		if step.teardown != nil {
+			rollbacks = append(rollbacks, step.teardown)
+		}
+
+		if err := step.setup(ctx); err != nil {
+			_, _ = fmt.Fprintln(os.Stdout, "⏳ Rolling back "+Name)
+
+			for i := len(rollbacks) - 1; i >= 0; i-- {
+				err = flaterrors.Join(err, rollbacks[i]())
+			}
+
+			return err
+		}
+	}
 
 	return nil
 }
@@ -156,6 +247,16 @@ func teardown() error {
 		return flaterrors.Join(err, errTearingDownLocalContainerRegistry)
 	}
 
+	envs, err := readEnvs()
+	if err != nil {
+		return flaterrors.Join(err, errTearingDownLocalContainerRegistry)
+	}
+
+	if envs.DryRun {
+		dryRunTeardown(os.Stdout, config)
+		return nil
+	}
+
 	// II. Create client.
 	cl, err := createKubeClient(config)
 	if err != nil {
@@ -163,8 +264,8 @@ func teardown() error {
 	}
 
 	// III. Initialize adapters
-	k8s := NewK8s(cl, config.Kindenv.KubeconfigPath, config.LocalContainerRegistry.Namespace)
-	containerRegistry := NewContainerRegistry(cl, config.LocalContainerRegistry.Namespace, nil)
+	k8s := NewK8s(cl, kubeconfig(config), config.LocalContainerRegistry.Namespace)
+	containerRegistry := NewContainerRegistry(cl, config.LocalContainerRegistry.Namespace, false, "", nil)
 
 	tls := NewTLS(
 		cl,
@@ -172,6 +273,14 @@ func teardown() error {
 		config.LocalContainerRegistry.Namespace,
 		containerRegistry.FQDN(), nil)
 
+	// The namespace is deleted: print the targeted cluster beforehand.
+	kubeContext, err := currentContext(kubeconfig(config))
+	if err != nil {
+		return flaterrors.Join(err, errTearingDownLocalContainerRegistry)
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "⏳ Using kubeconfig %q with context %q\n", kubeconfig(config), kubeContext)
+
 	// III. Tear down K8s
 	if err := k8s.Teardown(ctx); err != nil {
 		return flaterrors.Join(err, errTearingDownLocalContainerRegistry)
@@ -190,12 +299,7 @@ func teardown() error {
 var errCreatingKubernetesClient = errors.New("creating kubernetes client")
 
 func createKubeClient(config project.Config) (client.Client, error) { //nolint:ireturn
-	b, err := os.ReadFile(config.Kindenv.KubeconfigPath)
-	if err != nil {
-		return nil, flaterrors.Join(err, errCreatingKubernetesClient)
-	}
-
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig(b)
+	restConfig, err := readRESTConfig(kubeconfig(config))
 	if err != nil {
 		return nil, flaterrors.Join(err, errCreatingKubernetesClient)
 	}
@@ -217,3 +321,47 @@ func createKubeClient(config project.Config) (client.Client, error) { //nolint:i
 
 	return cl, nil
 }
+
+// kubeconfig returns the kubeconfig used by the kubernetes client and by the helm and kubectl subprocesses: the
+// KUBECONFIG env var if set, which may hold colon-separated paths, or the kubeconfig written by kindenv otherwise.
+func kubeconfig(config project.Config) string {
+	if v := os.Getenv("KUBECONFIG"); v != "" {
+		return v
+	}
+
+	return config.Kindenv.KubeconfigPath
+}
+
+// readRESTConfig reads the REST config from kubeconfig. Colon-separated kubeconfig paths are merged like kubectl does.
+func readRESTConfig(kubeconfig string) (*rest.Config, error) {
+	if paths := filepath.SplitList(kubeconfig); len(paths) > 1 {
+		loadingRules := &clientcmd.ClientConfigLoadingRules{Precedence: paths} //nolint:exhaustruct
+
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules,
+			&clientcmd.ConfigOverrides{}, //nolint:exhaustruct
+		).ClientConfig()
+	}
+
+	b, err := os.ReadFile(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientcmd.RESTConfigFromKubeConfig(b)
+}
+
+// currentContext returns the current context of kubeconfig. Colon-separated kubeconfig paths are merged like kubectl does.
+func currentContext(kubeconfig string) (string, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{Precedence: filepath.SplitList(kubeconfig)} //nolint:exhaustruct
+
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{}, //nolint:exhaustruct
+	).RawConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return rawConfig.CurrentContext, nil
+}
@@ -0,0 +1,230 @@
+//go:build unit
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clustersKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: kind
+  cluster:
+    server: https://127.0.0.1:6443
+- name: other
+  cluster:
+    server: https://other.example.com:6443
+users:
+- name: kind
+  user:
+    token: kind-token
+contexts:
+- name: kind
+  context:
+    cluster: kind
+    user: kind
+`
+
+	currentContextKubeconfig = `apiVersion: v1
+kind: Config
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: kind
+current-context: kind
+`
+
+	singleKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: kind
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: kind
+  user:
+    token: kind-token
+contexts:
+- name: kind
+  context:
+    cluster: kind
+    user: kind
+current-context: kind
+`
+)
+
+func TestKubeconfig(t *testing.T) {
+	config := project.Config{Kindenv: project.Kindenv{KubeconfigPath: "/repo/.ignore.kindenv.kubeconfig.yaml"}} //nolint:exhaustruct
+
+	t.Run("defaults to the kindenv kubeconfig", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", "")
+
+		assert.Equal(t, "/repo/.ignore.kindenv.kubeconfig.yaml", kubeconfig(config))
+	})
+
+	t.Run("honors KUBECONFIG", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", "/a:/b")
+
+		assert.Equal(t, "/a:/b", kubeconfig(config))
+	})
+}
+
+func TestReadRESTConfig(t *testing.T) {
+	dir := t.TempDir()
+	clustersPath := filepath.Join(dir, "clusters.yaml")
+	currentContextPath := filepath.Join(dir, "current-context.yaml")
+	singlePath := filepath.Join(dir, "single.yaml")
+
+	require.NoError(t, os.WriteFile(clustersPath, []byte(clustersKubeconfig), 0o600))
+	require.NoError(t, os.WriteFile(currentContextPath, []byte(currentContextKubeconfig), 0o600))
+	require.NoError(t, os.WriteFile(singlePath, []byte(singleKubeconfig), 0o600))
+
+	t.Run("merges multiple paths", func(t *testing.T) {
+		restConfig, err := readRESTConfig(strings.Join([]string{clustersPath, currentContextPath},
+			string(filepath.ListSeparator)))
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://127.0.0.1:6443", restConfig.Host)
+		assert.Equal(t, "kind-token", restConfig.BearerToken)
+	})
+
+	t.Run("reads a single path", func(t *testing.T) {
+		restConfig, err := readRESTConfig(singlePath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://127.0.0.1:6443", restConfig.Host)
+		assert.Equal(t, "kind-token", restConfig.BearerToken)
+	})
+
+	t.Run("fails without current-context", func(t *testing.T) {
+		_, err := readRESTConfig(clustersPath)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("fails if the kubeconfig does not exist", func(t *testing.T) {
+		_, err := readRESTConfig(filepath.Join(dir, "missing.yaml"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestCurrentContext(t *testing.T) {
+	dir := t.TempDir()
+	clustersPath := filepath.Join(dir, "clusters.yaml")
+	currentContextPath := filepath.Join(dir, "current-context.yaml")
+	singlePath := filepath.Join(dir, "single.yaml")
+
+	require.NoError(t, os.WriteFile(clustersPath, []byte(clustersKubeconfig), 0o600))
+	require.NoError(t, os.WriteFile(currentContextPath, []byte(currentContextKubeconfig), 0o600))
+	require.NoError(t, os.WriteFile(singlePath, []byte(singleKubeconfig), 0o600))
+
+	for _, tc := range []struct {
+		name       string
+		kubeconfig string
+		expected   string
+	}{
+		{name: "single path", kubeconfig: singlePath, expected: "kind"},
+		{
+			name:       "merged paths",
+			kubeconfig: strings.Join([]string{clustersPath, currentContextPath}, string(filepath.ListSeparator)),
+			expected:   "kind",
+		},
+		{name: "no current-context", kubeconfig: clustersPath, expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := currentContext(tc.kubeconfig)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRunSetupSteps(t *testing.T) {
+	errRegistry := errors.New("registry setup failed")
+	errTLS := errors.New("tls setup failed")
+
+	// calls records the setups and teardowns in the order they run.
+	newStep := func(calls *[]string, name string, setupErr error, withTeardown bool) setupStep {
+		step := setupStep{
+			setup: func(_ context.Context) error {
+				*calls = append(*calls, "setup "+name)
+				return setupErr
+			},
+			teardown: nil,
+		}
+
+		if withTeardown {
+			step.teardown = func() error {
+				*calls = append(*calls, "teardown "+name)
+				return nil
+			}
+		}
+
+		return step
+	}
+
+	for _, tc := range []struct {
+		name          string
+		tlsErr        error
+		registryErr   error
+		expectedErr   error
+		expectedCalls []string
+	}{
+		{
+			name: "all steps succeed",
+			expectedCalls: []string{
+				"setup k8s", "setup credentials", "setup tls", "setup registry",
+			},
+		},
+		{
+			name:        "the registry step fails",
+			registryErr: errRegistry,
+			expectedErr: errRegistry,
+			expectedCalls: []string{
+				"setup k8s", "setup credentials", "setup tls", "setup registry",
+				"teardown tls", "teardown credentials", "teardown k8s",
+			},
+		},
+		{
+			name:        "the failed step is rolled back",
+			tlsErr:      errTLS,
+			expectedErr: errTLS,
+			expectedCalls: []string{
+				"setup k8s", "setup credentials", "setup tls",
+				"teardown tls", "teardown credentials", "teardown k8s",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := make([]string, 0)
+
+			err := runSetupSteps(context.Background(), []setupStep{
+				newStep(&calls, "k8s", nil, true),
+				newStep(&calls, "credentials", nil, true),
+				newStep(&calls, "tls", tc.tlsErr, true),
+				newStep(&calls, "registry", tc.registryErr, false),
+			})
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
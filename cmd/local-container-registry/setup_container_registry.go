@@ -16,6 +16,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/utils/ptr"
@@ -29,20 +30,37 @@ const (
 	registryConfigConfigMapName = Name + "-config"
 	registryConfigFilename      = "config.yml"
 	registryConfigMountDir      = "/etc/docker/registry"
+
+	registryStoragePVCName     = Name + "-storage"
+	registryStorageMountDir    = "/var/lib/registry"
+	defaultRegistryStorageSize = "1Gi"
 )
 
 type ContainerRegistry struct {
 	client    client.Client
 	namespace string
 
+	persistent  bool
+	storageSize string
+
 	ec eventualconfig.EventualConfig
 }
 
-func NewContainerRegistry(cl client.Client, namespace string, ec eventualconfig.EventualConfig) *ContainerRegistry {
+func NewContainerRegistry(
+	cl client.Client,
+	namespace string,
+	persistent bool,
+	storageSize string,
+	ec eventualconfig.EventualConfig,
+) *ContainerRegistry {
 	return &ContainerRegistry{
 		client:    cl,
 		namespace: namespace,
-		ec:        ec,
+
+		persistent:  persistent,
+		storageSize: storageSize,
+
+		ec: ec,
 	}
 }
 
@@ -61,12 +79,19 @@ func (r *ContainerRegistry) Setup(ctx context.Context) error {
 		return flaterrors.Join(err, errSettingUpContainerRegistry)
 	}
 
-	// III. Create Deployment.
+	// III. Create PersistentVolumeClaim.
+	if r.persistent {
+		if err := r.createPVC(ctx, labels); err != nil {
+			return flaterrors.Join(err, errSettingUpContainerRegistry)
+		}
+	}
+
+	// IV. Create Deployment.
 	if err := r.createDeployment(ctx, labels); err != nil {
 		return flaterrors.Join(err, errSettingUpContainerRegistry)
 	}
 
-	// IV. Await Deployment readiness.
+	// V. Await Deployment readiness.
 	if err := r.awaitDeployment(ctx); err != nil {
 		return flaterrors.Join(err, errSettingUpContainerRegistry)
 	}
@@ -97,10 +122,16 @@ func (r *ContainerRegistry) createDeployment(ctx context.Context, labels map[str
 		return flaterrors.Join(err, errCreatingDeployment)
 	}
 
+	storageVolSrc, err := r.storageVolumeSource()
+	if err != nil {
+		return flaterrors.Join(err, errCreatingDeployment)
+	}
+
 	// II. Secret volume sources.
 	credVol := "credentials"
 	regVol := "registry-config"
 	tlsVol := "tls"
+	storageVol := "storage"
 
 	credVolSrc := corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
 		SecretName: credName,
@@ -141,6 +172,9 @@ func (r *ContainerRegistry) createDeployment(ctx context.Context, labels map[str
 						MountPath: tlsMount.Dir,
 						Name:      tlsVol,
 						ReadOnly:  true,
+					}, {
+						MountPath: registryStorageMountDir,
+						Name:      storageVol,
 					}},
 
 					Ports: []corev1.ContainerPort{{
@@ -159,6 +193,9 @@ func (r *ContainerRegistry) createDeployment(ctx context.Context, labels map[str
 				}, {
 					Name:         tlsVol,
 					VolumeSource: tlsVolSrc,
+				}, {
+					Name:         storageVol,
+					VolumeSource: storageVolSrc,
 				}},
 
 				RestartPolicy: corev1.RestartPolicyAlways,
@@ -174,6 +211,53 @@ func (r *ContainerRegistry) createDeployment(ctx context.Context, labels map[str
 	return nil
 }
 
+var errCreatingPVC = errors.New("creating persistent volume claim")
+
+func (r *ContainerRegistry) createPVC(ctx context.Context, labels map[string]string) error {
+	size, err := resource.ParseQuantity(r.StorageSize())
+	if err != nil {
+		return flaterrors.Join(err, errCreatingPVC)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{} //nolint:exhaustruct
+
+	pvc.Name = registryStoragePVCName
+	pvc.Namespace = r.namespace
+	pvc.Labels = labels
+
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+
+	if err := r.client.Create(ctx, pvc); err != nil {
+		return flaterrors.Join(err, errCreatingPVC)
+	}
+
+	return nil
+}
+
+// storageVolumeSource returns the PersistentVolumeClaim volume source if the registry is persistent. Otherwise, it
+// returns an EmptyDir volume source limited to the configured storage size, if any.
+func (r *ContainerRegistry) storageVolumeSource() (corev1.VolumeSource, error) {
+	if r.persistent {
+		return corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ //nolint:exhaustruct
+			ClaimName: registryStoragePVCName,
+		}}, nil
+	}
+
+	emptyDir := &corev1.EmptyDirVolumeSource{} //nolint:exhaustruct
+
+	if r.storageSize != "" {
+		size, err := resource.ParseQuantity(r.storageSize)
+		if err != nil {
+			return corev1.VolumeSource{}, err
+		}
+
+		emptyDir.SizeLimit = &size
+	}
+
+	return corev1.VolumeSource{EmptyDir: emptyDir}, nil //nolint:exhaustruct
+}
+
 var errAwaitingDeploymentReadiness = errors.New("awaiting deployment readiness")
 
 func (r *ContainerRegistry) awaitDeployment(ctx context.Context) error {
@@ -232,6 +316,19 @@ func (r *ContainerRegistry) ConfigMapName() string {
 	return registryConfigConfigMapName
 }
 
+func (r *ContainerRegistry) PVCName() string {
+	return registryStoragePVCName
+}
+
+// StorageSize returns the configured storage size, defaulting to defaultRegistryStorageSize.
+func (r *ContainerRegistry) StorageSize() string {
+	if r.storageSize == "" {
+		return defaultRegistryStorageSize
+	}
+
+	return r.storageSize
+}
+
 func (r *ContainerRegistry) Mount() Mount {
 	return Mount{
 		Dir:      registryConfigMountDir,
@@ -250,6 +347,8 @@ type registryConfig struct {
 	CACertPath     string
 	ServerCertPath string
 	ServerKeyPath  string
+
+	StorageRootDir string
 }
 
 const registryConfigTemplate = `version: 0.1
@@ -269,7 +368,7 @@ http:
 
 storage:
   filesystem:
-    rootdirectory: /var/lib/registry
+    rootdirectory: {{ .StorageRootDir }}
 `
 
 var errCreatingConfigMap = errors.New("creating configmap")
@@ -303,6 +402,7 @@ func (r *ContainerRegistry) createConfigMap(ctx context.Context, labels map[stri
 		CACertPath:     caCert.Path(),
 		ServerCertPath: tlsCert.Path(),
 		ServerKeyPath:  tlsKey.Path(),
+		StorageRootDir: registryStorageMountDir,
 	}
 
 	// II. Template file.
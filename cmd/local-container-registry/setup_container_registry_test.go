@@ -0,0 +1,101 @@
+//go:build unit
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/alexandremahdhaoui/tooling/internal/util/mocks/mockclient"
+)
+
+func TestCreatePVC(t *testing.T) {
+	labels := map[string]string{"app": Name}
+
+	t.Run("requests the storage size", func(t *testing.T) {
+		var actual *corev1.PersistentVolumeClaim
+
+		cl := mockclient.NewMockClient(t)
+		cl.EXPECT().Create(mock.Anything, mock.Anything).
+			Run(func(_ context.Context, obj client.Object, _ ...client.CreateOption) {
+				actual = obj.(*corev1.PersistentVolumeClaim) //nolint:forcetypeassert
+			}).
+			Return(nil)
+
+		r := NewContainerRegistry(cl, "lcr", true, "5Gi", nil)
+		require.NoError(t, r.createPVC(context.Background(), labels))
+
+		require.NotNil(t, actual)
+		assert.Equal(t, registryStoragePVCName, actual.Name)
+		assert.Equal(t, "lcr", actual.Namespace)
+		assert.Equal(t, labels, actual.Labels)
+		assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, actual.Spec.AccessModes)
+		assert.Equal(t, resource.MustParse("5Gi"), actual.Spec.Resources.Requests[corev1.ResourceStorage])
+	})
+
+	t.Run("fails on an invalid storage size", func(t *testing.T) {
+		// The mock fails the test if Create is called.
+		r := NewContainerRegistry(mockclient.NewMockClient(t), "lcr", true, "five gigs", nil)
+
+		assert.ErrorIs(t, r.createPVC(context.Background(), labels), errCreatingPVC)
+	})
+}
+
+func TestStorageVolumeSource(t *testing.T) {
+	sizeLimit := resource.MustParse("5Gi")
+
+	for _, tc := range []struct {
+		name        string
+		persistent  bool
+		storageSize string
+		expected    corev1.VolumeSource
+		expectedErr bool
+	}{
+		{
+			name:        "persistent storage uses the PVC",
+			persistent:  true,
+			storageSize: "5Gi",
+			expected: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ //nolint:exhaustruct
+				ClaimName: registryStoragePVCName,
+			}},
+		},
+		{
+			name:        "ephemeral storage is limited to the storage size",
+			persistent:  false,
+			storageSize: "5Gi",
+			expected:    corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}}, //nolint:exhaustruct
+		},
+		{
+			name:        "ephemeral storage is not limited by default",
+			persistent:  false,
+			storageSize: "",
+			expected:    corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}, //nolint:exhaustruct
+		},
+		{
+			name:        "fails on an invalid storage size",
+			persistent:  false,
+			storageSize: "five gigs",
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewContainerRegistry(nil, "lcr", tc.persistent, tc.storageSize, nil)
+
+			actual, err := r.storageVolumeSource()
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -106,6 +106,17 @@ func (c *Credential) Setup(ctx context.Context) error {
 	return nil
 }
 
+var errTearingDownCredentials = errors.New("failed to tear down credentials")
+
+// Teardown removes the credentials file. The credentials secret is deleted alongside the registry namespace.
+func (c *Credential) Teardown() error {
+	if err := os.Remove(c.credentialsPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return flaterrors.Join(err, errTearingDownCredentials)
+	}
+
+	return nil
+}
+
 var errWritingCredentialsToFile = errors.New("failed to write credentials to file")
 
 func (c *Credential) writeCredentials() error {
@@ -38,7 +38,7 @@ func (k *K8s) Setup(ctx context.Context) error {
 		return flaterrors.Join(err, errSettingUpK8sCluster)
 	}
 
-	// 2. set kubeconfig for the kubectl subprocess which applies the cert manager config
+	// 2. set kubeconfig for the helm and kubectl subprocesses, so they target the same cluster as the client
 	if err := os.Setenv("KUBECONFIG", k.kubeconfigPath); err != nil {
 		return flaterrors.Join(err, errSettingUpK8sCluster)
 	}
@@ -56,7 +56,7 @@ func (k *K8s) Teardown(ctx context.Context) error {
 		return flaterrors.Join(err, errTearingDownK8sCluster)
 	}
 
-	// 2. set kubeconfig for the kubectl subprocess which applies the cert manager config
+	// 2. set kubeconfig for the helm and kubectl subprocesses, so they target the same cluster as the client
 	if err := os.Setenv("KUBECONFIG", k.kubeconfigPath); err != nil {
 		return flaterrors.Join(err, errTearingDownK8sCluster)
 	}
@@ -113,7 +113,7 @@ func (t *TLS) ResourceName() string {
 var errTearingDownTLS = errors.New("tearing down TLS")
 
 func (t *TLS) Teardown() error {
-	cmd := exec.Command("kubectl", "delete", "-f", certManagerManifests)
+	cmd := exec.Command("kubectl", "delete", "--ignore-not-found", "-f", certManagerManifests)
 
 	if err := util.RunCmdWithStdPipes(cmd); err != nil {
 		return flaterrors.Join(err, errTearingDownTLS)
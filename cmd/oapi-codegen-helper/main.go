@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,13 +12,22 @@ import (
 
 	"github.com/alexandremahdhaoui/tooling/internal/util"
 	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/yaml"
 )
 
 const (
 	OAPICodegenEnvKey = "OAPI_CODEGEN"
+	// GofumptEnvKey is the optional executable used to format the generated code, e.g. "go run mvdan.cc/gofumpt@v0.6.0".
+	GofumptEnvKey = "GOFUMPT"
+	// FormatGeneratedEnvKey disables formatting of the generated code when set to "false".
+	FormatGeneratedEnvKey = "FORMAT_GENERATED"
 
 	errEnv = "OAPI_CODEGEN env var must be set"
 
+	warnFormatterNotSet = "⚠️ GOFUMPT env var is not set: generated code will not be formatted. " +
+		"Set FORMAT_GENERATED=false to disable formatting."
+
 	sourceFileTemplate  = "%s.%s.yaml"
 	zzGeneratedFilename = "zz_generated.oapi-codegen.go"
 
@@ -27,9 +38,6 @@ generate:
   client: true
   models: true
   embedded-spec: true
-output-options:
-  # to make sure that all types are generated
-  skip-prune: true
 `
 
 	serverTemplate = `---
@@ -40,12 +48,26 @@ generate:
   models: true
   std-http-server: true
   strict-server: true
-output-options:
-  skip-prune: true
 `
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == validateCommand {
+		config, err := project.ReadConfig()
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		if err := validate(config.OAPICodegenHelper); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, "successfully validated specs")
+		os.Exit(0)
+	}
+
 	executable := os.Getenv(OAPICodegenEnvKey)
 	if executable == "" {
 		_, _ = fmt.Fprintln(os.Stderr, errEnv)
@@ -58,7 +80,14 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := do(executable, config.OAPICodegenHelper); err != nil {
+	formatter := os.Getenv(GofumptEnvKey)
+	if os.Getenv(FormatGeneratedEnvKey) == "false" {
+		formatter = ""
+	} else if formatter == "" {
+		_, _ = fmt.Fprintln(os.Stderr, warnFormatterNotSet)
+	}
+
+	if err := do(executable, formatter, config.OAPICodegenHelper); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
@@ -67,7 +96,8 @@ func main() {
 	os.Exit(0)
 }
 
-func do(executable string, config project.OAPICodegenHelper) error {
+// do generates the code for each spec. The generated files are formatted with formatter unless it is empty.
+func do(executable, formatter string, config project.OAPICodegenHelper) error {
 	cmdName, args := parseExecutable(executable)
 	errChan := make(chan error)
 	wg := &sync.WaitGroup{}
@@ -76,7 +106,6 @@ func do(executable string, config project.OAPICodegenHelper) error {
 		i := i
 		for _, version := range config.Specs[i].Versions { // for each version
 			version := version
-			wg.Add(1)
 
 			// for each spec and each version in that spec:
 
@@ -95,16 +124,38 @@ func do(executable string, config project.OAPICodegenHelper) error {
 					template: serverTemplate,
 				},
 			} {
+				wg.Add(1)
+
 				go func() {
 					defer wg.Done()
 					if !pkg.opts.Enabled {
 						return
 					}
 
+					sourcePath := sourcePath
+					if config.Specs[i].BundleRefs {
+						bundledPath, cleanup, err := bundleSpec(sourcePath)
+						if err != nil {
+							errChan <- err // TODO: wrap err
+
+							return
+						}
+
+						defer cleanup()
+
+						sourcePath = bundledPath
+					}
+
 					outputPath := templateOutputPath(config, i, pkg.opts.PackageName)
-					templatedConfig := fmt.Sprintf(pkg.template, pkg.opts.PackageName, outputPath)
 
-					path, cleanup, err := writeTempCodegenConfig(templatedConfig)
+					templatedConfig, err := templateCodegenConfig(pkg.template, pkg.opts, outputPath)
+					if err != nil {
+						errChan <- err // TODO: wrap err
+
+						return
+					}
+
+					path, cleanup, err := writeTempFile("oapi-codegen-*.yaml", templatedConfig)
 					if err != nil {
 						errChan <- err // TODO: wrap err
 					}
@@ -118,6 +169,14 @@ func do(executable string, config project.OAPICodegenHelper) error {
 					args := append(args, "--config", path, sourcePath)
 					if err := util.RunCmdWithStdPipes(exec.Command(cmdName, args...)); err != nil {
 						errChan <- err // TODO: wrap err
+
+						return
+					}
+
+					if formatter != "" {
+						if err := formatGenerated(formatter, outputPath); err != nil {
+							errChan <- err
+						}
 					}
 				}()
 			}
@@ -138,6 +197,18 @@ func do(executable string, config project.OAPICodegenHelper) error {
 	return nil
 }
 
+// formatGenerated formats the generated file at path in place with the formatter executable, e.g. gofumpt.
+func formatGenerated(formatter, path string) error {
+	cmdName, args := parseExecutable(formatter)
+	args = append(args, "-w", path)
+
+	if err := util.RunCmdWithStdPipes(exec.Command(cmdName, args...)); err != nil {
+		return fmt.Errorf("formatting %q: %w", path, err)
+	}
+
+	return nil
+}
+
 func parseExecutable(executable string) (string, []string) {
 	split := strings.Split(executable, " ")
 
@@ -146,10 +217,10 @@ func parseExecutable(executable string) (string, []string) {
 
 // ---
 
-// writeTempCodegenConfig return the path to the generated config file, a cleanup function and an error.
-func writeTempCodegenConfig(templatedConfig string) (string, func(), error) {
+// writeTempFile return the path to the written temp file, a cleanup function and an error.
+func writeTempFile(pattern, content string) (string, func(), error) {
 	// 1. create tempfile
-	tempFile, err := os.CreateTemp("", "oapi-codegen-*.yaml")
+	tempFile, err := os.CreateTemp("", pattern)
 	if err != nil {
 		return "", nil, err // TODO: wrap err
 	}
@@ -160,7 +231,7 @@ func writeTempCodegenConfig(templatedConfig string) (string, func(), error) {
 	}
 
 	// 3. write to file.
-	if _, err := tempFile.WriteString(templatedConfig); err != nil {
+	if _, err := tempFile.WriteString(content); err != nil {
 		cleanup()
 
 		return "", nil, err // TODO: wrap err
@@ -176,6 +247,56 @@ func writeTempCodegenConfig(templatedConfig string) (string, func(), error) {
 	return tempFile.Name(), cleanup, nil
 }
 
+// bundleSpec resolves the external $refs of the spec located at sourcePath into a single self-contained spec.
+// It returns the path to the bundled temp spec, a cleanup function and an error.
+func bundleSpec(sourcePath string) (string, func(), error) {
+	doc, err := loadSpec(sourcePath)
+	if err != nil {
+		return "", nil, err // TODO: wrap err
+	}
+
+	doc.InternalizeRefs(context.Background(), nil)
+
+	b, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", nil, err // TODO: wrap err
+	}
+
+	return writeTempFile("oapi-codegen-bundle-*.yaml", string(b))
+}
+
+// loadSpec loads the spec located at sourcePath, which is either a local path or an http(s) URL.
+func loadSpec(sourcePath string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	if u, err := url.Parse(sourcePath); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return loader.LoadFromURI(u)
+	}
+
+	return loader.LoadFromFile(sourcePath)
+}
+
+// templateCodegenConfig templates the oapi-codegen config and appends its "output-options" block. The options
+// configured in opts take precedence over the defaults.
+func templateCodegenConfig(template string, opts project.GenOpts, outputPath string) (string, error) {
+	outputOptions := map[string]any{
+		// to make sure that all types are generated
+		"skip-prune": true,
+	}
+
+	for k, v := range opts.OutputOptions {
+		outputOptions[k] = v
+	}
+
+	b, err := yaml.Marshal(map[string]any{"output-options": outputOptions})
+	if err != nil {
+		return "", err // TODO: wrap err
+	}
+
+	return fmt.Sprintf(template, opts.PackageName, outputPath) + string(b), nil
+}
+
 func templateOutputPath(config project.OAPICodegenHelper, index int, packageName string) string {
 	destDir := config.Defaults.DestinationDir
 	if config.Specs[index].DestinationDir != "" { // it takes precedence over defaults.
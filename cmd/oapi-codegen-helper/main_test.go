@@ -0,0 +1,169 @@
+//go:build unit
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	unformattedCode = "package generated\nfunc  Hello( )   string {return \"hello\"}\n"
+	formattedCode   = "package generated\n\nfunc Hello() string { return \"hello\" }\n"
+
+	// fakeCodegen writes unformatted code to the output of the oapi-codegen config passed as "--config <path>".
+	fakeCodegen = `out=$(sed -n 's/^output: //p' "$2")
+printf '` + "package generated\\nfunc  Hello( )   string {return \"hello\"}\\n" + `' > "$out"
+`
+)
+
+// gofmt returns the path to the gofmt executable of the go toolchain, which is used as a stand-in for gofumpt.
+func gofmt(t *testing.T) string {
+	t.Helper()
+
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	require.NoError(t, err)
+
+	return filepath.Join(strings.TrimSpace(string(goroot)), "bin", "gofmt")
+}
+
+func TestFormatGenerated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), zzGeneratedFilename)
+	require.NoError(t, os.WriteFile(path, []byte(unformattedCode), 0o600))
+
+	require.NoError(t, formatGenerated(gofmt(t), path))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formattedCode, string(b))
+}
+
+func TestDo(t *testing.T) {
+	dir := t.TempDir()
+	codegenPath := filepath.Join(dir, "codegen.sh")
+	require.NoError(t, os.WriteFile(codegenPath, []byte(fakeCodegen), 0o600))
+
+	config := project.OAPICodegenHelper{
+		Specs: []project.OAPICodegenHelperSpec{{ //nolint:exhaustruct
+			Name:     "example",
+			Versions: []string{"v1"},
+			Client:   project.GenOpts{Enabled: true, PackageName: "exampleclient"}, //nolint:exhaustruct
+			Server:   project.GenOpts{Enabled: true, PackageName: "exampleserver"}, //nolint:exhaustruct
+		}},
+		Defaults: project.OAPICodegenHelperDefaults{
+			SourceDir:      dir,
+			DestinationDir: dir,
+		},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		formatter string
+		expected  string
+	}{
+		{name: "formats the generated files", formatter: gofmt(t), expected: formattedCode},
+		{name: "does not format without formatter", formatter: "", expected: unformattedCode},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, do("sh "+codegenPath, tc.formatter, config))
+
+			for _, pkg := range []string{"exampleclient", "exampleserver"} {
+				b, err := os.ReadFile(templateOutputPath(config, 0, pkg))
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(b), pkg)
+			}
+		})
+	}
+}
+
+const (
+	bundledSpec = `openapi: 3.0.3
+info:
+  title: example
+  version: v1
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: a pet
+          content:
+            application/json:
+              schema:
+                $ref: ./schemas.yaml#/components/schemas/Pet
+`
+
+	externalSchemas = `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`
+)
+
+func TestBundleSpec(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "example.v1.yaml"), []byte(bundledSpec), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas.yaml"), []byte(externalSchemas), 0o600))
+
+	path, cleanup, err := bundleSpec(filepath.Join(dir, "example.v1.yaml"))
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// The external schema is copied into the components of the spec, and every $ref points to the spec itself.
+	assert.NotContains(t, string(b), "schemas.yaml#")
+	assert.Contains(t, string(b), "$ref: '#/components/schemas/schemas_Pet'")
+	assert.Equal(t, 1, strings.Count(string(b), "$ref:"))
+
+	// The bundled spec is self-contained: it loads without its directory.
+	require.NoError(t, validateSpec(path))
+
+	cleanup()
+	assert.NoFileExists(t, path)
+}
+
+func TestTemplateCodegenConfig(t *testing.T) {
+	const header = "---\npackage: exampleclient\noutput: out/zz_generated.oapi-codegen.go\n" +
+		"generate:\n  client: true\n  models: true\n  embedded-spec: true\n"
+
+	for _, tc := range []struct {
+		name          string
+		outputOptions map[string]any
+		expected      string
+	}{
+		{
+			name:          "skips pruning by default",
+			outputOptions: nil,
+			expected:      header + "output-options:\n  skip-prune: true\n",
+		},
+		{
+			name:          "merges the output options",
+			outputOptions: map[string]any{"nullable-type": true},
+			expected:      header + "output-options:\n  nullable-type: true\n  skip-prune: true\n",
+		},
+		{
+			name:          "the output options override skip-prune",
+			outputOptions: map[string]any{"skip-prune": false},
+			expected:      header + "output-options:\n  skip-prune: false\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := project.GenOpts{PackageName: "exampleclient", OutputOptions: tc.outputOptions} //nolint:exhaustruct
+
+			actual, err := templateCodegenConfig(clientTemplate, opts, "out/zz_generated.oapi-codegen.go")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+)
+
+const validateCommand = "validate"
+
+// validate concurrently validates every configured spec and version against the OpenAPI schema, without generating
+// any code. It returns the errors of all invalid specs.
+func validate(config project.OAPICodegenHelper) error {
+	var (
+		errs error
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	for i := range config.Specs {
+		for _, version := range config.Specs[i].Versions {
+			sourcePath := templateSourcePath(config, i, version)
+			name := config.Specs[i].Name
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				err := validateSpec(sourcePath)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					errs = flaterrors.Join(errs, fmt.Errorf("❌ spec %q version %q (%s): %w", name, version, sourcePath, err))
+					return
+				}
+
+				_, _ = fmt.Printf("✅ spec %q version %q (%s) is valid\n", name, version, sourcePath)
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func validateSpec(sourcePath string) error {
+	doc, err := loadSpec(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	return doc.Validate(context.Background())
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validSpec = `openapi: 3.0.3
+info:
+  title: example
+  version: v1
+paths: {}
+`
+
+	invalidSpec = `openapi: 3.0.3
+info:
+  title: example
+  version: v1
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: not-a-type
+`
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "valid.v1.yaml"), []byte(validSpec), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.v1.yaml"), []byte(invalidSpec), 0o600))
+
+	newConfig := func(names ...string) project.OAPICodegenHelper {
+		config := project.OAPICodegenHelper{ //nolint:exhaustruct
+			Defaults: project.OAPICodegenHelperDefaults{SourceDir: dir, DestinationDir: dir},
+		}
+
+		for _, name := range names {
+			config.Specs = append(config.Specs, project.OAPICodegenHelperSpec{ //nolint:exhaustruct
+				Name:     name,
+				Versions: []string{"v1"},
+			})
+		}
+
+		return config
+	}
+
+	t.Run("validateSpec", func(t *testing.T) {
+		assert.NoError(t, validateSpec(filepath.Join(dir, "valid.v1.yaml")))
+		assert.Error(t, validateSpec(filepath.Join(dir, "invalid.v1.yaml")))
+	})
+
+	t.Run("accepts valid specs", func(t *testing.T) {
+		assert.NoError(t, validate(newConfig("valid")))
+	})
+
+	t.Run("names the invalid spec", func(t *testing.T) {
+		err := validate(newConfig("valid", "invalid"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `spec "invalid" version "v1"`)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "invalid.v1.yaml"))
+		assert.NotContains(t, err.Error(), `spec "valid"`)
+	})
+}
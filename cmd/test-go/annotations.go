@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ----------------------------------------------------- GITHUB ANNOTATIONS ----------------------------------------- //
+
+// reFailureLocation matches the location of a failed assertion in the output of a test, e.g. "    foo_test.go:42: msg".
+var reFailureLocation = regexp.MustCompile(`^\s*([\w.\-]+_test\.go):(\d+): (.*)$`)
+
+type annotation struct {
+	File    string
+	Line    string
+	Title   string
+	Message string
+}
+
+// String formats the annotation as a GitHub Actions workflow command.
+func (a annotation) String() string {
+	return fmt.Sprintf("::error file=%s,line=%s,title=%s::%s", escapeProperty(a.File), a.Line, escapeProperty(a.Title),
+		escapeAnnotation(a.Message))
+}
+
+// annotationsFromJUnit returns one annotation per failed assertion found in the JUnit report. File paths are made
+// relative to the module root by trimming modulePath from the package names.
+func annotationsFromJUnit(suites junitTestSuites, modulePath string) []annotation {
+	out := make([]annotation, 0)
+
+	for _, suite := range suites.Suites {
+		for _, tc := range suite.TestCases {
+			if tc.Failure == nil {
+				continue
+			}
+
+			dir := strings.TrimPrefix(strings.TrimPrefix(tc.ClassName, modulePath), "/")
+
+			for _, line := range strings.Split(tc.Failure.Contents, "\n") {
+				match := reFailureLocation.FindStringSubmatch(line)
+				if match == nil {
+					continue
+				}
+
+				out = append(out, annotation{
+					File:    path.Join(dir, match[1]),
+					Line:    match[2],
+					Title:   tc.Name,
+					Message: match[3],
+				})
+			}
+		}
+	}
+
+	return out
+}
+
+// escapeAnnotation escapes the characters GitHub Actions interprets in the message of a workflow command.
+func escapeAnnotation(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes the characters GitHub Actions interprets in the properties of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer(",", "%2C", ":", "%3A").Replace(escapeAnnotation(s))
+}
+
+// readModulePath returns the module path declared in the go.mod of the current directory.
+func readModulePath() (string, error) {
+	f, err := os.Open("go.mod")
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if modulePath, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(modulePath), `"`), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("no module directive found in go.mod") //nolint:err113
+}
+
+// printAnnotations prints GitHub Actions annotations for each failed assertion found in the JUnit report. It is
+// best-effort: annotations are a convenience and must not change the outcome of the test run.
+func printAnnotations(junitPath string) {
+	suites, err := readJUnit(junitPath)
+	if err != nil {
+		return
+	}
+
+	modulePath, err := readModulePath()
+	if err != nil {
+		return
+	}
+
+	for _, a := range annotationsFromJUnit(suites, modulePath) {
+		fmt.Println(a.String())
+	}
+}
@@ -0,0 +1,99 @@
+//go:build unit
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		input              string
+		expectedAnnotation string
+		expectedProperty   string
+	}{
+		{
+			name:               "percent",
+			input:              "100%",
+			expectedAnnotation: "100%25",
+			expectedProperty:   "100%25",
+		},
+		{
+			name:               "carriage return and newline",
+			input:              "a\r\nb",
+			expectedAnnotation: "a%0D%0Ab",
+			expectedProperty:   "a%0D%0Ab",
+		},
+		{
+			name:               "colon and comma are only escaped in properties",
+			input:              "TestFoo/a:b,c",
+			expectedAnnotation: "TestFoo/a:b,c",
+			expectedProperty:   "TestFoo/a%3Ab%2Cc",
+		},
+		{
+			name:               "escaped sequences are not unescaped",
+			input:              "%0A",
+			expectedAnnotation: "%250A",
+			expectedProperty:   "%250A",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAnnotation, escapeAnnotation(tc.input))
+			assert.Equal(t, tc.expectedProperty, escapeProperty(tc.input))
+		})
+	}
+}
+
+func TestAnnotationsFromJUnit(t *testing.T) {
+	const modulePath = "github.com/alexandremahdhaoui/tooling"
+
+	suites := junitTestSuites{
+		Suites: []junitTestSuite{
+			{ //nolint:exhaustruct
+				Name: modulePath + "/pkg/flaterrors",
+				TestCases: []junitTestCase{
+					{ //nolint:exhaustruct
+						ClassName: modulePath + "/pkg/flaterrors",
+						Name:      "TestJoin",
+						Failure: &junitFailure{
+							Message: "Failed",
+							Contents: "=== RUN   TestJoin\n" +
+								"    flaterrors_test.go:20: expected 2 errors, got 1\n" +
+								"    flaterrors_test.go:24: expected: 100%\n" +
+								"--- FAIL: TestJoin (0.00s)\n",
+						},
+					},
+					{ //nolint:exhaustruct
+						ClassName: modulePath + "/pkg/flaterrors",
+						Name:      "TestPasses",
+					},
+				},
+			},
+			{ //nolint:exhaustruct
+				Name: modulePath,
+				TestCases: []junitTestCase{
+					{ //nolint:exhaustruct
+						ClassName: modulePath,
+						Name:      "TestRoot",
+						Failure:   &junitFailure{Message: "Failed", Contents: "\troot_test.go:7: boom\n"},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []annotation{
+		{File: "pkg/flaterrors/flaterrors_test.go", Line: "20", Title: "TestJoin", Message: "expected 2 errors, got 1"},
+		{File: "pkg/flaterrors/flaterrors_test.go", Line: "24", Title: "TestJoin", Message: "expected: 100%"},
+		{File: "root_test.go", Line: "7", Title: "TestRoot", Message: "boom"},
+	}
+
+	actual := annotationsFromJUnit(suites, modulePath)
+	assert.Equal(t, expected, actual)
+
+	assert.Equal(t, "::error file=pkg/flaterrors/flaterrors_test.go,line=24,title=TestJoin::expected: 100%25",
+		actual[1].String())
+}
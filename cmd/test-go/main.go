@@ -1,11 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"regexp"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/alexandremahdhaoui/tooling/internal/util"
 	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
@@ -15,6 +22,16 @@ import (
 // ----------------------------------------------------- ENVS ------------------------------------------------------- //
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == mergeCommand {
+		if err := merge(); err != nil {
+			fmt.Printf("❌ Error while merging %s test reports\n%s\n", os.Getenv("TEST_TAG"), err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ %s test reports merged successfully\n", os.Getenv("TEST_TAG"))
+		os.Exit(0)
+	}
+
 	if err := run(); err != nil {
 		printFailure(err)
 		os.Exit(1)
@@ -35,44 +52,242 @@ func run() error {
 		return flaterrors.Join(err, errors.New("error reading environment variables"))
 	}
 
-	cmd := envs.Gotestsum
-	args := []string{
-		"--junitfile", fmt.Sprintf(".ignore.test-%s.xml", envs.TestTag),
-		"--",
-		"-tags", envs.TestTag,
-		"-race", "./...", "-count=1",
-		"-cover", "-coverprofile", fmt.Sprintf(".ignore.test-%s-coverage.out", envs.TestTag),
-		"./...",
+	if err := validateEnvs(envs); err != nil {
+		printUsage()
+		return err
+	}
+
+	if err := createReportDir(envs.TestReportDir); err != nil {
+		return err
 	}
 
+	packages := []string{"./..."}
+
+	if envs.TestShardTotal > 1 {
+		if envs.TestShardIndex < 0 || envs.TestShardIndex >= envs.TestShardTotal {
+			printUsage()
+			return fmt.Errorf("TEST_SHARD_INDEX must be in [0, %d), got %d", //nolint:err113
+				envs.TestShardTotal, envs.TestShardIndex)
+		}
+
+		allPackages, err := listPackages(envs.TestTag)
+		if err != nil {
+			return flaterrors.Join(err, errors.New("error listing packages"))
+		}
+
+		packages = shardPackages(allPackages, envs.TestShardIndex, envs.TestShardTotal)
+
+		if len(packages) == 0 {
+			fmt.Printf("⚠️ No package to test in shard %d of %d\n", envs.TestShardIndex, envs.TestShardTotal)
+			return nil
+		}
+	}
+
+	junitPath, coverprofilePath := reportPaths(envs.TestReportDir, reportName(envs))
+
+	cmd := envs.Gotestsum
+	args := buildTestArgs(envs, packages)
+
 	if slice := strings.Split(envs.Gotestsum, " "); len(slice) > 1 {
 		cmd = slice[0]
 		args = append(slice[1:], args...)
 	}
 
-	if err := util.RunCmdWithStdPipes(exec.Command(cmd, args...)); err != nil {
-		return flaterrors.Join(err, errors.New("error while running gotestsum"))
+	// gotestsum runs in its own process group to be killed with its children on timeout, hence it no longer receives
+	// the signals sent to the foreground process group: they are forwarded by cancelling the context.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if envs.TestTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, envs.TestTimeout)
+		defer cancel()
+	}
+
+	// The output is captured to classify failures. Stdout and stderr share the same writer, hence exec.Cmd will not
+	// write to it concurrently.
+	output := bytes.NewBuffer(make([]byte, 0))
+	w := io.MultiWriter(os.Stdout, output)
+
+	// gotestsum runs "go test", which runs the test binaries: the whole process group is killed on timeout.
+	gotestsum := exec.Command(cmd, args...)
+	gotestsum.Stdout = w
+	gotestsum.Stderr = w
+
+	err := util.RunCmdWithStdPipesContext(ctx, gotestsum)
+
+	// The summary is best-effort: reports may be missing, e.g. if the tests failed to compile.
+	if summary, summaryErr := readSummary(envs.TestTag, junitPath, coverprofilePath); summaryErr == nil {
+		_, _ = fmt.Fprintln(os.Stderr, summary.String())
+	}
+
+	if err != nil && os.Getenv("GITHUB_ACTIONS") == "true" {
+		printAnnotations(junitPath)
+	}
+
+	if err != nil {
+		return classifyRunError(ctx, err, output.String())
+	}
+
+	// go test succeeds when no test matched the tag.
+	if reNoTests.MatchString(output.String()) {
+		fmt.Printf("⚠️ No test matched the %q tag\n", envs.TestTag)
+	}
+
+	return nil
+}
+
+// validateEnvs returns an error if the go test flags set by envs are out of range.
+func validateEnvs(envs Envs) error {
+	if envs.TestCount < 1 {
+		return fmt.Errorf("TEST_COUNT must be a positive integer, got %d", envs.TestCount) //nolint:err113
+	}
+
+	if envs.TestParallel < 0 || envs.TestP < 0 {
+		return fmt.Errorf("TEST_PARALLEL and TEST_P must be >= 0, got %d and %d", //nolint:err113
+			envs.TestParallel, envs.TestP)
 	}
 
 	return nil
 }
 
+// reportName returns the name of the reports of the tests selected by envs, i.e. the test tag suffixed by the shard.
+func reportName(envs Envs) string {
+	if envs.TestShardTotal > 1 {
+		return fmt.Sprintf("%s-shard-%d-of-%d", envs.TestTag, envs.TestShardIndex, envs.TestShardTotal)
+	}
+
+	return envs.TestTag
+}
+
+// buildTestArgs returns the gotestsum arguments running the tests of pkgs as configured by envs.
+func buildTestArgs(envs Envs, pkgs []string) []string {
+	junitPath, coverprofilePath := reportPaths(envs.TestReportDir, reportName(envs))
+
+	args := []string{
+		"--junitfile", junitPath,
+		"--",
+		"-tags", envs.TestTag,
+	}
+
+	if envs.TestRace {
+		args = append(args, "-race")
+	}
+
+	if envs.TestShuffle != "" {
+		args = append(args, fmt.Sprintf("-shuffle=%s", envs.TestShuffle))
+	}
+
+	if envs.TestParallel > 0 {
+		args = append(args, fmt.Sprintf("-parallel=%d", envs.TestParallel))
+	}
+
+	if envs.TestP > 0 {
+		args = append(args, fmt.Sprintf("-p=%d", envs.TestP))
+	}
+
+	args = append(args,
+		fmt.Sprintf("-count=%d", envs.TestCount),
+		"-cover", "-coverprofile", coverprofilePath,
+	)
+
+	return append(args, pkgs...)
+}
+
+var (
+	errTestRunTimedOut    = errors.New("test run timed out")
+	errTestRunInterrupted = errors.New("test run interrupted")
+
+	errBuildFailed = errors.New("build failure: tests failed to compile")
+	errTestsFailed = errors.New("test failure: one or more tests failed")
+	errNoTests     = errors.New("no tests: no test matched the provided tag")
+
+	// The summary of gotestsum, e.g.: "DONE 12 tests, 1 skipped, 1 failure, 1 error in 1.045s". Errors are reported
+	// for packages that failed to build or to set up.
+	reBuildErrors = regexp.MustCompile(`DONE \d+ tests?(, \d+ skipped)?(, \d+ failures?)?, \d+ errors?`)
+	reFailedTests = regexp.MustCompile(`DONE \d+ tests?(, \d+ skipped)?, \d+ failures?`)
+	reNoTests     = regexp.MustCompile(`DONE 0 tests in `)
+)
+
+// classifyRunError wraps the error of a gotestsum run with the reason it failed: the run timed out or was interrupted if
+// ctx is done, otherwise the failure is classified based on its output.
+func classifyRunError(ctx context.Context, err error, output string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return flaterrors.Join(err, errTestRunTimedOut)
+	}
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return flaterrors.Join(err, errTestRunInterrupted)
+	}
+
+	return flaterrors.Join(err, classifyFailure(output), errors.New("error while running gotestsum"))
+}
+
+// classifyFailure returns an error describing why gotestsum failed based on its output, or nil if the failure could not
+// be classified.
+func classifyFailure(output string) error {
+	switch {
+	case strings.Contains(output, "[build failed]"), strings.Contains(output, "[setup failed]"),
+		reBuildErrors.MatchString(output):
+		return errBuildFailed
+	case strings.Contains(output, "--- FAIL"), strings.Contains(output, "=== FAIL"), reFailedTests.MatchString(output):
+		return errTestsFailed
+	case reNoTests.MatchString(output):
+		return errNoTests
+	default:
+		return nil
+	}
+}
+
 // ----------------------------------------------------- ENVS ------------------------------------------------------- //
 
 type Envs struct {
 	TestTag   string `env:"TEST_TAG,required"`
 	Gotestsum string `env:"GOTESTSUM,required"`
+
+	TestTimeout   time.Duration `env:"TEST_TIMEOUT"`
+	TestReportDir string        `env:"TEST_REPORT_DIR" envDefault:"."`
+	TestRace      bool          `env:"TEST_RACE"       envDefault:"true"`
+	TestCount     int           `env:"TEST_COUNT"      envDefault:"1"`
+	TestShuffle   string        `env:"TEST_SHUFFLE"`
+	TestParallel  int           `env:"TEST_PARALLEL"`
+	TestP         int           `env:"TEST_P"`
+
+	TestShardIndex int `env:"TEST_SHARD_INDEX"`
+	TestShardTotal int `env:"TEST_SHARD_TOTAL"`
 }
 
 // ----------------------------------------------------- PRINT HELPERS ----------------------------------------------- //
 
 const usage = `USAGE
 
-GOTESTSUM="" TEST_TAG="" %s
+GOTESTSUM="" TEST_TAG="" %[1]s
 
 With:
     GOTESTSUM   Path to go-test-sum or "go run" command.
     TEST_TAG    Tag to target the test, i.e.: "unit", "integration", "functional", or "e2e".
+
+Optional:
+    TEST_TIMEOUT    Maximum duration of the whole test run (e.g. "10m"). The run is killed when exceeded.
+    TEST_REPORT_DIR Directory where the JUnit and coverage reports are written. Defaults to ".".
+    TEST_RACE       Enables the race detector. Set to "false" to disable it. Defaults to "true".
+    TEST_COUNT      Number of times each test is run (go test -count). Defaults to "1".
+    TEST_SHUFFLE    Randomizes the execution order of tests (go test -shuffle), i.e.: "on", "off" or a seed.
+    TEST_PARALLEL   Maximum number of tests run in parallel within a package (go test -parallel).
+    TEST_P          Maximum number of packages built and tested in parallel (go test -p).
+    TEST_SHARD_INDEX Index of the shard to run, in [0, TEST_SHARD_TOTAL). Defaults to "0".
+    TEST_SHARD_TOTAL Number of shards the packages are split across. Sharding is disabled if lower than 2.
+
+When GITHUB_ACTIONS is "true", failed assertions are reported as GitHub Actions error annotations.
+
+MERGE
+
+TEST_TAG="" %[1]s merge
+
+Merges the reports of all shards found in TEST_REPORT_DIR into the reports of the TEST_TAG tests. If TEST_SHARD_TOTAL
+is set, only the reports of shards split across TEST_SHARD_TOTAL are merged. Otherwise, all reports must share the same
+shard total.
 `
 
 func printUsage() {
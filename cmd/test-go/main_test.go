@@ -0,0 +1,256 @@
+//go:build unit
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/tooling/internal/util"
+	"github.com/caarlos0/env/v11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The outputs below are samples of "gotestsum --format pkgname".
+const (
+	buildErrorOutput = `✖  pkg/flaterrors
+
+=== Errors
+pkg/flaterrors/flaterrors_test.go:12:2: undefined: foo
+
+DONE 0 tests, 1 error in 0.312s
+`
+
+	buildFailedOutput = `✖  pkg/flaterrors [build failed]
+
+DONE 0 tests, 1 error in 0.298s
+`
+
+	testFailureOutput = `✖  pkg/flaterrors (3ms)
+✓  pkg/project (cached)
+
+=== Failed
+=== FAIL: pkg/flaterrors TestJoin (0.00s)
+    flaterrors_test.go:20: expected 2 errors, got 1
+
+DONE 12 tests, 1 skipped, 1 failure in 1.045s
+`
+
+	testFailureAndBuildErrorOutput = `✖  pkg/flaterrors (3ms)
+✖  pkg/project
+
+=== Failed
+=== FAIL: pkg/flaterrors TestJoin (0.00s)
+    flaterrors_test.go:20: expected 2 errors, got 1
+
+=== Errors
+pkg/project/config_test.go:8:2: undefined: bar
+
+DONE 12 tests, 1 failure, 1 error in 1.201s
+`
+
+	noTestsOutput = `∅  pkg/flaterrors
+∅  pkg/project
+
+DONE 0 tests in 0.204s
+`
+)
+
+func TestClassifyFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		output   string
+		expected error
+	}{
+		{name: "build error without [build failed]", output: buildErrorOutput, expected: errBuildFailed},
+		{name: "build failed", output: buildFailedOutput, expected: errBuildFailed},
+		{name: "test failure", output: testFailureOutput, expected: errTestsFailed},
+		{name: "test failure and build error", output: testFailureAndBuildErrorOutput, expected: errBuildFailed},
+		{name: "no tests", output: noTestsOutput, expected: errNoTests},
+		{name: "unknown", output: "signal: killed\n", expected: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classifyFailure(tc.output))
+		})
+	}
+}
+
+func TestClassifyRunError(t *testing.T) {
+	t.Run("a run killed by TEST_TIMEOUT timed out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		// A fake gotestsum hanging forever.
+		err := util.RunCmdWithStdPipesContext(ctx, exec.Command("sh", "-c", "sleep 30"))
+		require.Error(t, err)
+
+		err = classifyRunError(ctx, err, "")
+		assert.True(t, errors.Is(err, errTestRunTimedOut))
+		assert.False(t, errors.Is(err, errTestRunInterrupted))
+	})
+
+	t.Run("an interrupted run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := classifyRunError(ctx, errors.New("signal: killed"), "")
+		assert.True(t, errors.Is(err, errTestRunInterrupted))
+	})
+
+	t.Run("a failed run is classified based on its output", func(t *testing.T) {
+		err := classifyRunError(context.Background(), errors.New("exit status 1"), testFailureOutput)
+		assert.True(t, errors.Is(err, errTestsFailed))
+		assert.False(t, errors.Is(err, errTestRunTimedOut))
+	})
+}
+
+func TestBuildTestArgs(t *testing.T) {
+	pkgs := []string{"./..."}
+
+	for _, tc := range []struct {
+		name     string
+		envs     Envs
+		expected []string
+	}{
+		{
+			name: "enables the race detector by default",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestRace: true, TestCount: 1}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-race", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "disables the race detector",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestRace: false, TestCount: 1}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "shuffles the tests",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestCount: 1, TestShuffle: "on"}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-shuffle=on", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "shuffles the tests with a seed",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestCount: 1, TestShuffle: "42"}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-shuffle=42", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "runs the tests several times",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestCount: 3}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-count=3",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "omits -parallel and -p when zero",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestCount: 1}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+		{
+			name: "sets -parallel and -p when positive",
+			envs: Envs{TestTag: "unit", TestReportDir: ".", TestCount: 1, TestParallel: 4, TestP: 2}, //nolint:exhaustruct
+			expected: []string{
+				"--junitfile", ".ignore.test-unit.xml", "--", "-tags", "unit", "-parallel=4", "-p=2", "-count=1",
+				"-cover", "-coverprofile", ".ignore.test-unit-coverage.out", "./...",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, buildTestArgs(tc.envs, pkgs))
+		})
+	}
+
+	t.Run("reads TEST_RACE", func(t *testing.T) {
+		for _, tc := range []struct {
+			testRace *string
+			expected bool
+		}{
+			{testRace: nil, expected: true},
+			{testRace: ptr("false"), expected: false},
+		} {
+			t.Setenv("TEST_TAG", "unit")
+			t.Setenv("GOTESTSUM", "gotestsum")
+			t.Setenv("TEST_RACE", "")
+			require.NoError(t, os.Unsetenv("TEST_RACE"))
+
+			if tc.testRace != nil {
+				t.Setenv("TEST_RACE", *tc.testRace)
+			}
+
+			envs := Envs{} //nolint:exhaustruct // unmarshal
+			require.NoError(t, env.Parse(&envs))
+
+			assert.Equal(t, tc.expected, slices.Contains(buildTestArgs(envs, pkgs), "-race"))
+		}
+	})
+}
+
+func TestValidateEnvs(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		envs        Envs
+		expectedErr string
+	}{
+		{
+			name: "accepts a positive TEST_COUNT",
+			envs: Envs{TestCount: 1}, //nolint:exhaustruct
+		},
+		{
+			name:        "rejects a zero TEST_COUNT",
+			envs:        Envs{TestCount: 0}, //nolint:exhaustruct
+			expectedErr: "TEST_COUNT must be a positive integer, got 0",
+		},
+		{
+			name: "accepts zero TEST_PARALLEL and TEST_P",
+			envs: Envs{TestCount: 1, TestParallel: 0, TestP: 0}, //nolint:exhaustruct
+		},
+		{
+			name: "accepts positive TEST_PARALLEL and TEST_P",
+			envs: Envs{TestCount: 1, TestParallel: 4, TestP: 2}, //nolint:exhaustruct
+		},
+		{
+			name:        "rejects a negative TEST_PARALLEL",
+			envs:        Envs{TestCount: 1, TestParallel: -1}, //nolint:exhaustruct
+			expectedErr: "TEST_PARALLEL and TEST_P must be >= 0, got -1 and 0",
+		},
+		{
+			name:        "rejects a negative TEST_P",
+			envs:        Envs{TestCount: 1, TestP: -1}, //nolint:exhaustruct
+			expectedErr: "TEST_PARALLEL and TEST_P must be >= 0, got 0 and -1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateEnvs(tc.envs)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+	"github.com/caarlos0/env/v11"
+)
+
+const mergeCommand = "merge"
+
+type MergeEnvs struct {
+	TestTag        string `env:"TEST_TAG,required"`
+	TestReportDir  string `env:"TEST_REPORT_DIR"  envDefault:"."`
+	TestShardTotal int    `env:"TEST_SHARD_TOTAL"`
+}
+
+var (
+	errMergingReports         = errors.New("error merging test reports")
+	errNoShardReports         = errors.New("no shard report found")
+	errInconsistentShardTotal = errors.New("shard reports have different shard totals: set TEST_SHARD_TOTAL or " +
+		"remove stale reports")
+
+	reShardTotal = regexp.MustCompile(`-shard-\d+-of-(\d+)\.xml$`)
+)
+
+// merge merges the JUnit reports and the coverprofiles written by each shard into the reports of the test tag.
+func merge() error {
+	envs := MergeEnvs{} //nolint:exhaustruct // unmarshal
+
+	if err := env.Parse(&envs); err != nil {
+		printUsage()
+		return flaterrors.Join(err, errMergingReports)
+	}
+
+	junitPaths, err := shardJUnitPaths(envs.TestReportDir, envs.TestTag, envs.TestShardTotal)
+	if err != nil {
+		return flaterrors.Join(err, errMergingReports)
+	}
+
+	coverprofilePaths := make([]string, 0, len(junitPaths))
+	for _, path := range junitPaths {
+		coverprofilePaths = append(coverprofilePaths, strings.TrimSuffix(path, ".xml")+"-coverage.out")
+	}
+
+	junitPath, coverprofilePath := reportPaths(envs.TestReportDir, envs.TestTag)
+
+	if err := mergeJUnit(junitPath, junitPaths); err != nil {
+		return flaterrors.Join(err, errMergingReports)
+	}
+
+	if err := mergeCoverprofiles(coverprofilePath, coverprofilePaths); err != nil {
+		return flaterrors.Join(err, errMergingReports)
+	}
+
+	summary, err := readSummary(envs.TestTag, junitPath, coverprofilePath)
+	if err != nil {
+		return flaterrors.Join(err, errMergingReports)
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, summary.String())
+
+	return nil
+}
+
+// shardJUnitPaths returns the paths to the JUnit reports of the shards found in dir. Only the reports of shards split
+// across total are returned if total is set. Otherwise, all reports must share the same total, hence reports left by a
+// previous run with another total are not merged.
+func shardJUnitPaths(dir, testTag string, total int) ([]string, error) {
+	totalPattern := "*"
+	if total > 0 {
+		totalPattern = strconv.Itoa(total)
+	}
+
+	pattern, _ := reportPaths(dir, fmt.Sprintf("%s-shard-*-of-%s", testTag, totalPattern))
+
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, flaterrors.Join(fmt.Errorf("with pattern: %q", pattern), errNoShardReports) //nolint:err113
+	}
+
+	totals := make(map[string]struct{})
+	for _, path := range paths {
+		if match := reShardTotal.FindStringSubmatch(path); match != nil {
+			totals[match[1]] = struct{}{}
+		}
+	}
+
+	if len(totals) > 1 {
+		return nil, flaterrors.Join(fmt.Errorf("found: %q", paths), errInconsistentShardTotal) //nolint:err113
+	}
+
+	return paths, nil
+}
+
+// ----------------------------------------------------- JUNIT ------------------------------------------------------ //
+
+// rawTestSuites preserves the test suites verbatim while aggregating the counters of the root element.
+type rawTestSuites struct {
+	XMLName  xml.Name       `xml:"testsuites"`
+	Tests    int            `xml:"tests,attr"`
+	Failures int            `xml:"failures,attr"`
+	Errors   int            `xml:"errors,attr"`
+	Time     float64        `xml:"time,attr"`
+	Suites   []rawTestSuite `xml:"testsuite"`
+}
+
+type rawTestSuite struct {
+	XMLName  xml.Name   `xml:"testsuite"`
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
+func mergeJUnit(out string, paths []string) error {
+	merged := rawTestSuites{} //nolint:exhaustruct
+
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		suites := rawTestSuites{} //nolint:exhaustruct // unmarshal
+
+		if err := xml.Unmarshal(b, &suites); err != nil {
+			return flaterrors.Join(err, fmt.Errorf("with path: %q", path)) //nolint:err113
+		}
+
+		merged.Tests += suites.Tests
+		merged.Failures += suites.Failures
+		merged.Errors += suites.Errors
+		merged.Time += suites.Time
+		merged.Suites = append(merged.Suites, suites.Suites...)
+	}
+
+	b, err := xml.MarshalIndent(merged, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	b = append(append([]byte(xml.Header), b...), '\n')
+
+	return os.WriteFile(out, b, 0o644) //nolint:gosec
+}
+
+// ----------------------------------------------------- COVERAGE --------------------------------------------------- //
+
+// mergeCoverprofiles concatenates the coverprofiles, keeping only the first "mode:" line. Shards test disjoint
+// packages, hence the profiles do not overlap.
+func mergeCoverprofiles(out string, paths []string) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+
+	for i, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range strings.SplitAfter(string(b), "\n") {
+			if strings.HasPrefix(line, "mode:") && i > 0 {
+				continue
+			}
+
+			buf.WriteString(line)
+		}
+	}
+
+	return os.WriteFile(out, buf.Bytes(), 0o644) //nolint:gosec
+}
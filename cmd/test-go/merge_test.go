@@ -0,0 +1,124 @@
+//go:build unit
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	shard0JUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" errors="0" time="1.500000">
+	<testsuite tests="2" failures="1" time="1.500000" name="example.com/m/pkg/a">
+		<testcase classname="example.com/m/pkg/a" name="TestA" time="0.100000"></testcase>
+		<testcase classname="example.com/m/pkg/a" name="TestB" time="0.200000">
+			<failure message="Failed" type="">=== RUN   TestB&#xA;    a_test.go:12: boom&#xA;</failure>
+		</testcase>
+	</testsuite>
+</testsuites>
+`
+
+	shard1JUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="0" errors="0" time="2.000000">
+	<testsuite tests="3" failures="0" skipped="1" time="2.000000" name="example.com/m/pkg/b">
+		<testcase classname="example.com/m/pkg/b" name="TestC" time="0.100000"></testcase>
+		<testcase classname="example.com/m/pkg/b" name="TestD" time="0.100000"></testcase>
+		<testcase classname="example.com/m/pkg/b" name="TestE" time="0.000000">
+			<skipped message="skipped"></skipped>
+		</testcase>
+	</testsuite>
+</testsuites>
+`
+
+	// 8 of 10 statements are covered.
+	shard0Coverprofile = `mode: atomic
+example.com/m/pkg/a/a.go:3.20,5.2 8 1
+example.com/m/pkg/a/a.go:7.20,9.2 2 0
+`
+
+	// 15 of 30 statements are covered.
+	shard1Coverprofile = `mode: atomic
+example.com/m/pkg/b/b.go:3.20,5.2 15 3
+example.com/m/pkg/b/b.go:7.20,9.2 15 0
+`
+)
+
+func writeShardReports(t *testing.T, dir string, index, total int, junit, coverprofile string) {
+	t.Helper()
+
+	junitPath, coverprofilePath := reportPaths(dir, fmt.Sprintf("unit-shard-%d-of-%d", index, total))
+
+	require.NoError(t, os.WriteFile(junitPath, []byte(junit), 0o600))
+	require.NoError(t, os.WriteFile(coverprofilePath, []byte(coverprofile), 0o600))
+}
+
+func TestMergeReports(t *testing.T) {
+	dir := t.TempDir()
+	writeShardReports(t, dir, 0, 2, shard0JUnit, shard0Coverprofile)
+	writeShardReports(t, dir, 1, 2, shard1JUnit, shard1Coverprofile)
+
+	junitPaths, err := shardJUnitPaths(dir, "unit", 0)
+	require.NoError(t, err)
+	require.Len(t, junitPaths, 2)
+
+	junitPath, coverprofilePath := reportPaths(dir, "unit")
+	_, shard0CoverprofilePath := reportPaths(dir, "unit-shard-0-of-2")
+	_, shard1CoverprofilePath := reportPaths(dir, "unit-shard-1-of-2")
+
+	require.NoError(t, mergeJUnit(junitPath, junitPaths))
+	require.NoError(t, mergeCoverprofiles(coverprofilePath, []string{shard0CoverprofilePath, shard1CoverprofilePath}))
+
+	actual, err := readSummary("unit", junitPath, coverprofilePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, actual.Passed)
+	assert.Equal(t, 1, actual.Failed)
+	assert.Equal(t, 1, actual.Skipped)
+	assert.InDelta(t, 3.5, actual.Seconds, 1e-9)
+	// Coverage is weighted by statements: (8 + 15) / (10 + 30), not the mean of 80% and 50%.
+	assert.InDelta(t, 57.5, actual.Coverage, 1e-9)
+}
+
+func TestShardJUnitPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeShardReports(t, dir, 0, 2, shard0JUnit, shard0Coverprofile)
+	writeShardReports(t, dir, 1, 2, shard1JUnit, shard1Coverprofile)
+
+	t.Run("returns the reports of the shards", func(t *testing.T) {
+		paths, err := shardJUnitPaths(dir, "unit", 0)
+		require.NoError(t, err)
+
+		assert.Len(t, paths, 2)
+	})
+
+	t.Run("returns no report for another tag", func(t *testing.T) {
+		_, err := shardJUnitPaths(dir, "integration", 0)
+
+		assert.ErrorIs(t, err, errNoShardReports)
+	})
+
+	// Reports left by a previous run split across 3 shards.
+	writeShardReports(t, dir, 2, 3, shard1JUnit, shard1Coverprofile)
+
+	t.Run("rejects reports with different shard totals", func(t *testing.T) {
+		_, err := shardJUnitPaths(dir, "unit", 0)
+
+		assert.ErrorIs(t, err, errInconsistentShardTotal)
+	})
+
+	t.Run("only returns the reports of the given shard total", func(t *testing.T) {
+		paths, err := shardJUnitPaths(dir, "unit", 2)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{
+			filepath.Join(dir, ".ignore.test-unit-shard-0-of-2.xml"),
+			filepath.Join(dir, ".ignore.test-unit-shard-1-of-2.xml"),
+		}, paths)
+	})
+}
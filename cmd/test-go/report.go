@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+)
+
+// reportPaths returns the paths to the JUnit report and to the coverprofile named after name.
+func reportPaths(dir, name string) (string, string) {
+	return filepath.Join(dir, fmt.Sprintf(".ignore.test-%s.xml", name)),
+		filepath.Join(dir, fmt.Sprintf(".ignore.test-%s-coverage.out", name))
+}
+
+// createReportDir creates the directory where the reports are written, including its missing parents.
+func createReportDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return flaterrors.Join(err, errors.New("error creating test report directory"))
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------- JUNIT ------------------------------------------------------ //
+
+type junitTestSuites struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Skipped   *struct{}     `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func readJUnit(path string) (junitTestSuites, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return junitTestSuites{}, err
+	}
+
+	out := junitTestSuites{} //nolint:exhaustruct // unmarshal
+
+	if err := xml.Unmarshal(b, &out); err != nil {
+		return junitTestSuites{}, err
+	}
+
+	return out, nil
+}
+
+// ----------------------------------------------------- COVERAGE --------------------------------------------------- //
+
+// readCoverage returns the percentage of statements covered by the tests, computed from a go coverprofile.
+func readCoverage(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	var total, covered int
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// E.g.: "github.com/alexandremahdhaoui/tooling/pkg/flaterrors/flaterrors.go:20.33,23.2 1 1"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "mode:") { //nolint:gomnd
+			continue
+		}
+
+		numStmt, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, err
+		}
+
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, err
+		}
+
+		total += numStmt
+		if count > 0 {
+			covered += numStmt
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	return 100 * float64(covered) / float64(total), nil
+}
+
+// ----------------------------------------------------- SUMMARY ---------------------------------------------------- //
+
+type summary struct {
+	TestTag  string
+	Passed   int
+	Failed   int
+	Skipped  int
+	Seconds  float64
+	Coverage float64
+}
+
+// String formats the summary as a one-line human-readable report, e.g.:
+// "unit: 42 passed, 1 failed (12.3s, 87.4% cov)".
+func (s summary) String() string {
+	skipped := ""
+	if s.Skipped > 0 {
+		skipped = fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+
+	return fmt.Sprintf("%s: %d passed, %d failed%s (%.1fs, %.1f%% cov)",
+		s.TestTag, s.Passed, s.Failed, skipped, s.Seconds, s.Coverage)
+}
+
+func readSummary(testTag, junitPath, coverprofilePath string) (summary, error) {
+	suites, err := readJUnit(junitPath)
+	if err != nil {
+		return summary{}, err
+	}
+
+	coverage, err := readCoverage(coverprofilePath)
+	if err != nil {
+		return summary{}, err
+	}
+
+	out := summary{TestTag: testTag, Coverage: coverage} //nolint:exhaustruct
+
+	for _, suite := range suites.Suites {
+		out.Seconds += suite.Time
+
+		for _, tc := range suite.TestCases {
+			switch {
+			case tc.Failure != nil:
+				out.Failed++
+			case tc.Skipped != nil:
+				out.Skipped++
+			default:
+				out.Passed++
+			}
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,72 @@
+//go:build unit
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryString(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		summary  summary
+		expected string
+	}{
+		{
+			name: "without skipped tests",
+			summary: summary{
+				TestTag:  "unit",
+				Passed:   42,
+				Failed:   1,
+				Skipped:  0,
+				Seconds:  12.34,
+				Coverage: 87.44,
+			},
+			expected: "unit: 42 passed, 1 failed (12.3s, 87.4% cov)",
+		},
+		{
+			name: "with skipped tests",
+			summary: summary{
+				TestTag:  "integration",
+				Passed:   3,
+				Failed:   0,
+				Skipped:  2,
+				Seconds:  0.05,
+				Coverage: 0,
+			},
+			expected: "integration: 3 passed, 0 failed, 2 skipped (0.1s, 0.0% cov)",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.summary.String())
+		})
+	}
+}
+
+func TestCreateReportDir(t *testing.T) {
+	t.Run("creates nested directories", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "a", "b", "c")
+
+		require.NoError(t, createReportDir(dir))
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	})
+
+	t.Run("accepts an existing directory", func(t *testing.T) {
+		assert.NoError(t, createReportDir(t.TempDir()))
+	})
+
+	t.Run("fails if a parent is a file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		assert.Error(t, createReportDir(filepath.Join(file, "dir")))
+	})
+}
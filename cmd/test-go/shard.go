@@ -0,0 +1,34 @@
+package main
+
+import (
+	"hash/fnv"
+	"os/exec"
+	"strings"
+)
+
+// listPackages returns the import paths of the packages matching "./..." for the given build tag.
+func listPackages(testTag string) ([]string, error) {
+	b, err := exec.Command("go", "list", "-tags", testTag, "./...").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(b)), nil
+}
+
+// shardPackages returns the packages assigned to the shard at index. Packages are assigned by hashing their import
+// path, hence shards are disjoint and cover all packages, and a package stays in the same shard as others are added.
+func shardPackages(packages []string, index, total int) []string {
+	out := make([]string, 0)
+
+	for _, pkg := range packages {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(pkg))
+
+		if int(h.Sum32()%uint32(total)) == index { //nolint:gosec // total is validated to be positive.
+			out = append(out, pkg)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,40 @@
+//go:build unit
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardPackages(t *testing.T) {
+	packages := make([]string, 0)
+	for i := range 20 {
+		packages = append(packages, fmt.Sprintf("github.com/alexandremahdhaoui/tooling/pkg/p%d", i))
+	}
+
+	for _, total := range []int{2, 3, 5} {
+		t.Run(fmt.Sprintf("%d shards", total), func(t *testing.T) {
+			seen := make(map[string]int)
+
+			for index := range total {
+				for _, pkg := range shardPackages(packages, index, total) {
+					seen[pkg]++
+				}
+			}
+
+			// Every package is assigned to exactly one shard.
+			assert.Len(t, seen, len(packages))
+
+			for _, pkg := range packages {
+				assert.Equal(t, 1, seen[pkg], pkg)
+			}
+		})
+	}
+
+	t.Run("is stable across runs", func(t *testing.T) {
+		assert.Equal(t, shardPackages(packages, 1, 3), shardPackages(packages, 1, 3))
+	})
+}
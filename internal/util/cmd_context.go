@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
+)
+
+// ErrCmdCanceled is returned by RunCmdWithStdPipesContext when the command is killed because its context is done. The
+// returned error also wraps the context error, i.e. context.DeadlineExceeded or context.Canceled.
+var ErrCmdCanceled = errors.New("command killed: context done")
+
+// RunCmdWithStdPipesContext runs cmd like RunCmdWithStdPipes, but kills it when ctx is done. On unix, a cancellable
+// command is started in its own process group, and the whole group is killed, hence children started by the command
+// (e.g. by a shell wrapper or "go run") do not outlive it. Stdout and stderr default to the std pipes if unset.
+func RunCmdWithStdPipesContext(ctx context.Context, cmd *exec.Cmd) error {
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	// A command that cannot be cancelled stays in the process group of the caller, so it keeps receiving the signals
+	// sent to the foreground process group, e.g. on Ctrl-C.
+	if ctx.Done() == nil {
+		return cmd.Run()
+	}
+
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	killed := make(chan bool)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = killProcessGroup(cmd)
+			killed <- true
+		case <-done:
+			killed <- false
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+
+	// The context may be done after the command exited but before the watcher observed it: a command that succeeded
+	// is not reported as cancelled.
+	if <-killed && err != nil {
+		return flaterrors.Join(err, ctx.Err(), ErrCmdCanceled)
+	}
+
+	return err
+}
@@ -0,0 +1,90 @@
+//go:build unit
+
+package util_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandremahdhaoui/tooling/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCmdWithStdPipesContext(t *testing.T) {
+	t.Run("kills the process group when the deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		// The shell prints the pid of its background child, which must not outlive it.
+		stdout := bytes.NewBuffer(make([]byte, 0))
+		cmd := exec.Command("sh", "-c", "sleep 30 & echo $!; sleep 30")
+		cmd.Stdout = stdout
+
+		start := time.Now()
+		err := util.RunCmdWithStdPipesContext(ctx, cmd)
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.True(t, errors.Is(err, util.ErrCmdCanceled))
+
+		pid, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return !isAlive(pid) }, 2*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("returns the error of a failing command", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := util.RunCmdWithStdPipesContext(ctx, exec.Command("sh", "-c", "exit 3"))
+
+		exitErr := &exec.ExitError{}
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.False(t, errors.Is(err, util.ErrCmdCanceled))
+	})
+
+	t.Run("does not report a command that succeeded as cancelled", func(t *testing.T) {
+		// The context is done around the time the command exits, so that it is sometimes done after the command exited
+		// but before its watcher observed it.
+		for i := range 50 {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i%10)*time.Millisecond)
+
+			err := util.RunCmdWithStdPipesContext(ctx, exec.Command("true"))
+
+			cancel()
+
+			if err != nil {
+				exitErr := &exec.ExitError{}
+				assert.True(t, errors.As(err, &exitErr), "unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("runs a command that cannot be cancelled", func(t *testing.T) {
+		assert.NoError(t, util.RunCmdWithStdPipesContext(context.Background(), exec.Command("true")))
+	})
+}
+
+// isAlive returns false if the process does not exist or is a zombie, i.e. it was killed but not reaped yet by its new
+// parent.
+func isAlive(pid int) bool {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+
+	// E.g. "1234 (sleep) Z ...": the state follows the command name.
+	fields := strings.Fields(string(b[strings.LastIndexByte(string(b), ')')+1:]))
+
+	return len(fields) > 0 && fields[0] != "Z"
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package util
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op: process groups are only supported on unix.
+func setProcessGroup(_ *exec.Cmd) {}
+
+// killProcessGroup only kills the process of cmd: its children are not killed.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -3,6 +3,8 @@ package project
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/alexandremahdhaoui/tooling/pkg/flaterrors"
 
@@ -23,10 +25,20 @@ type Config struct {
 	OAPICodegenHelper      OAPICodegenHelper      `json:"oapiCodegenHelper"`
 }
 
-var errReadingProjectConfig = errors.New("error reading project config")
+var (
+	errReadingProjectConfig = errors.New("error reading project config")
+	errRepoRootNotFound     = errors.New("cannot find repository root: no " + ConfigPath + " or .git found")
+)
 
+// ReadConfig reads the project config at the root of the repository. Relative paths in the config are resolved against
+// the repository root, hence tools may be run from any subdirectory.
 func ReadConfig() (Config, error) {
-	b, err := os.ReadFile(ConfigPath) //nolint:varnamelen
+	root, err := FindRepoRoot()
+	if err != nil {
+		return Config{}, flaterrors.Join(err, errReadingProjectConfig)
+	}
+
+	b, err := os.ReadFile(filepath.Join(root, ConfigPath)) //nolint:varnamelen
 	if err != nil {
 		return Config{}, flaterrors.Join(err, errReadingProjectConfig)
 	}
@@ -37,5 +49,64 @@ func ReadConfig() (Config, error) {
 		return Config{}, flaterrors.Join(err, errReadingProjectConfig)
 	}
 
+	out.resolvePaths(root)
+
 	return out, nil
 }
+
+// FindRepoRoot returns the closest directory, starting from the working directory and walking up, that contains the
+// project config. If no project config is found, it returns the closest directory containing a .git entry.
+func FindRepoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	gitRoot := ""
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ConfigPath)); err == nil {
+			return dir, nil
+		}
+
+		// The project config may live above a nested repository, e.g. a submodule: the walk goes on.
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && gitRoot == "" {
+			gitRoot = dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	if gitRoot == "" {
+		return "", errRepoRootNotFound
+	}
+
+	return gitRoot, nil
+}
+
+func (c *Config) resolvePaths(root string) {
+	c.Kindenv.KubeconfigPath = resolvePath(root, c.Kindenv.KubeconfigPath)
+	c.LocalContainerRegistry.CredentialPath = resolvePath(root, c.LocalContainerRegistry.CredentialPath)
+	c.LocalContainerRegistry.CaCrtPath = resolvePath(root, c.LocalContainerRegistry.CaCrtPath)
+	c.OAPICodegenHelper.Defaults.SourceDir = resolvePath(root, c.OAPICodegenHelper.Defaults.SourceDir)
+	c.OAPICodegenHelper.Defaults.DestinationDir = resolvePath(root, c.OAPICodegenHelper.Defaults.DestinationDir)
+
+	for i := range c.OAPICodegenHelper.Specs {
+		c.OAPICodegenHelper.Specs[i].Source = resolvePath(root, c.OAPICodegenHelper.Specs[i].Source)
+		c.OAPICodegenHelper.Specs[i].DestinationDir = resolvePath(root, c.OAPICodegenHelper.Specs[i].DestinationDir)
+	}
+}
+
+// resolvePath returns path joined to root if it is relative. Empty paths, absolute paths and URLs are left untouched.
+func resolvePath(root, path string) string {
+	if path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+
+	return filepath.Join(root, path)
+}
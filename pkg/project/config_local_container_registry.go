@@ -5,4 +5,10 @@ type LocalContainerRegistry struct {
 	CredentialPath string `json:"credentialPath"`
 	CaCrtPath      string `json:"caCrtPath"`
 	Namespace      string `json:"namespace"`
+
+	// Persistent provisions a PersistentVolumeClaim to store the registry data. Defaults to ephemeral storage.
+	Persistent bool `json:"persistent"`
+	// StorageSize is the size of the registry storage, e.g. "5Gi". It is the size limit of the ephemeral storage or the
+	// requested size of the PersistentVolumeClaim.
+	StorageSize string `json:"storageSize"`
 }
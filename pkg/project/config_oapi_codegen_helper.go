@@ -14,11 +14,18 @@ type OAPICodegenHelperSpec struct {
 
 	Source         string `json:"source,omitempty"`
 	DestinationDir string `json:"destinationDir,omitempty"`
+
+	// BundleRefs resolves external $refs into a single self-contained spec before generating code.
+	BundleRefs bool `json:"bundleRefs,omitempty"`
 }
 
 type GenOpts struct {
 	Enabled     bool   `json:"enabled"`
 	PackageName string `json:"packageName"`
+
+	// OutputOptions are merged into the "output-options" of the generated oapi-codegen config, e.g.:
+	// "nullable-type", "prune" or "client-type-name".
+	OutputOptions map[string]any `json:"outputOptions,omitempty"`
 }
 
 type OAPICodegenHelperDefaults struct {
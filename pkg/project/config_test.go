@@ -0,0 +1,117 @@
+//go:build unit
+
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandremahdhaoui/tooling/pkg/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `name: test
+kindenv:
+  kubeconfigPath: .ignore.kindenv.kubeconfig.yaml
+localContainerRegistry:
+  credentialPath: /etc/local-container-registry/credentials.yaml
+  caCrtPath: .ignore.ca.crt
+oapiCodegenHelper:
+  defaults:
+    sourceDir: ./api
+    destinationDir: ./pkg/generated
+  specs:
+    - name: local
+      versions: [v1]
+      source: api/local.v1.yaml
+    - name: remote
+      versions: [v1]
+      source: https://example.com/remote.v1.yaml
+`
+
+// chdir changes the working directory to dir for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+// newRepo returns the root of a repository containing marker, and a subdirectory of it.
+func newRepo(t *testing.T, marker, content string) (string, string) {
+	t.Helper()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	subdir := filepath.Join(root, "cmd", "tool")
+	require.NoError(t, os.MkdirAll(subdir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, marker), []byte(content), 0o600))
+
+	return root, subdir
+}
+
+func TestFindRepoRoot(t *testing.T) {
+	for _, marker := range []string{project.ConfigPath, ".git"} {
+		t.Run(marker, func(t *testing.T) {
+			root, subdir := newRepo(t, marker, "")
+			chdir(t, subdir)
+
+			actual, err := project.FindRepoRoot()
+			require.NoError(t, err)
+
+			assert.Equal(t, root, actual)
+		})
+	}
+
+	t.Run("prefers the project config over a closer .git", func(t *testing.T) {
+		root, subdir := newRepo(t, project.ConfigPath, "")
+		require.NoError(t, os.Mkdir(filepath.Join(subdir, ".git"), 0o755))
+		chdir(t, subdir)
+
+		actual, err := project.FindRepoRoot()
+		require.NoError(t, err)
+
+		assert.Equal(t, root, actual)
+	})
+
+	t.Run("falls back to the closest .git", func(t *testing.T) {
+		root, subdir := newRepo(t, ".git", "")
+		nested := filepath.Join(subdir, "nested")
+		require.NoError(t, os.MkdirAll(filepath.Join(nested, ".git"), 0o755))
+		chdir(t, nested)
+
+		actual, err := project.FindRepoRoot()
+		require.NoError(t, err)
+
+		assert.Equal(t, nested, actual)
+		assert.NotEqual(t, root, actual)
+	})
+}
+
+func TestReadConfig(t *testing.T) {
+	root, subdir := newRepo(t, project.ConfigPath, testConfig)
+	chdir(t, subdir)
+
+	config, err := project.ReadConfig()
+	require.NoError(t, err)
+
+	t.Run("resolves relative paths against the repository root", func(t *testing.T) {
+		assert.Equal(t, filepath.Join(root, ".ignore.kindenv.kubeconfig.yaml"), config.Kindenv.KubeconfigPath)
+		assert.Equal(t, filepath.Join(root, ".ignore.ca.crt"), config.LocalContainerRegistry.CaCrtPath)
+		assert.Equal(t, filepath.Join(root, "api"), config.OAPICodegenHelper.Defaults.SourceDir)
+		assert.Equal(t, filepath.Join(root, "pkg", "generated"), config.OAPICodegenHelper.Defaults.DestinationDir)
+		assert.Equal(t, filepath.Join(root, "api", "local.v1.yaml"), config.OAPICodegenHelper.Specs[0].Source)
+	})
+
+	t.Run("leaves absolute paths, URLs and empty paths untouched", func(t *testing.T) {
+		assert.Equal(t, "/etc/local-container-registry/credentials.yaml", config.LocalContainerRegistry.CredentialPath)
+		assert.Equal(t, "https://example.com/remote.v1.yaml", config.OAPICodegenHelper.Specs[1].Source)
+		assert.Empty(t, config.OAPICodegenHelper.Specs[0].DestinationDir)
+	})
+}